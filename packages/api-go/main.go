@@ -1,12 +1,22 @@
 package main
 
 import (
+	"context"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 
+	"github.com/otaviopontes/api-go/src/app"
 	"github.com/otaviopontes/api-go/src/config"
-	"github.com/otaviopontes/api-go/src/router"
+	"github.com/otaviopontes/api-go/src/logging"
+	middlewares "github.com/otaviopontes/api-go/src/middleware"
+	"github.com/otaviopontes/api-go/src/realtime"
+	"github.com/otaviopontes/api-go/src/timelines"
+	"github.com/otaviopontes/api-go/src/tracing"
 	"github.com/rs/cors"
 )
 
@@ -30,20 +40,97 @@ import (
 // @BasePath /api
 func main() {
 	config.Load()
+	logging.Setup(config.LogLevel)
 
-	r := router.Generate()
+	if err := run(); err != nil {
+		slog.Error("server stopped", slog.String("error", err.Error()))
+		os.Exit(1)
+	}
+}
+
+// run serves the API until SIGINT or SIGTERM, then stops accepting
+// connections, lets the in-flight requests finish within
+// config.ShutdownTimeout, and stops the workers before closing the pools.
+func run() error {
+	shutdownTracing, err := tracing.Setup(context.Background(), tracing.Options{
+		Exporter:    config.TracingExporter,
+		Endpoint:    config.TracingEndpoint,
+		Insecure:    config.TracingInsecure,
+		File:        config.TracingFile,
+		SampleRatio: config.TracingSampleRatio,
+	})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			slog.Error("flushing traces failed", slog.String("error", err.Error()))
+		}
+	}()
+
+	application, err := app.New()
+	if err != nil {
+		return err
+	}
+	defer application.Close()
+
+	timelines.MaxEntries = int64(config.TimelineSize)
+	timelines.Start(application.DB, application.Redis, config.FanoutWorkers)
+	defer timelines.Stop()
+
+	realtime.StreamSize = int64(config.EventStreamSize)
+	realtime.Start(application.Redis)
+	defer realtime.Stop()
+
+	r := application.Router()
 
 	cors := cors.New(cors.Options{
 		AllowedOrigins:   []string{config.FrontEndUrl},
 		AllowedHeaders:   []string{"*"},
 		AllowedMethods:   []string{"GET", "PATCH", "POST", "PUT", "OPTIONS", "DELETE"},
+		ExposedHeaders:   []string{middlewares.RequestIdHeader},
 		AllowCredentials: true,
 	})
 
-	// Insert the middleware
-	handler := cors.Handler(r)
+	server := &http.Server{
+		Addr:           fmt.Sprintf(":%d", config.Port),
+		Handler:        cors.Handler(r),
+		ReadTimeout:    time.Duration(config.ReadTimeout) * time.Second,
+		WriteTimeout:   time.Duration(config.WriteTimeout) * time.Second,
+		IdleTimeout:    time.Duration(config.IdleTimeout) * time.Second,
+		MaxHeaderBytes: config.MaxHeaderBytes,
+	}
+	// Live connections never go idle, so they are closed as soon as the
+	// shutdown starts instead of holding it until the deadline.
+	server.RegisterOnShutdown(realtime.Stop)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	failed := make(chan error, 1)
+	go func() {
+		slog.Info("listening", slog.Int("port", config.Port))
+		failed <- server.ListenAndServe()
+	}()
+
+	select {
+	case err := <-failed:
+		return err
+	case <-ctx.Done():
+	}
+
+	slog.Info("shutting down")
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), time.Duration(config.ShutdownTimeout)*time.Second)
+	defer cancel()
 
-	fmt.Printf("Listening to port %d", config.Port)
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		// The deadline passed: cut the requests still running.
+		server.Close()
+		return err
+	}
 
-	log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", config.Port), handler))
+	return nil
 }
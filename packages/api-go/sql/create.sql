@@ -6,18 +6,192 @@ CREATE TABLE users(
     nick VARCHAR(50) NOT NULL UNIQUE,
     email VARCHAR(50) NOT NULL UNIQUE,
     password VARCHAR(100) NOT NULL,
-    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+    role VARCHAR(20) NOT NULL DEFAULT 'user' CHECK (role IN ('user', 'moderator', 'admin')),
+    suspendedAt TIMESTAMP,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', nick), 'A') ||
+        setweight(to_tsvector('simple', name), 'B')
+    ) STORED
 );
 
+CREATE INDEX users_search_vector_idx ON users USING GIN (search_vector);
+
 CREATE TABLE posts(
     id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
     title VARCHAR(50) NOT NULL,
     content VARCHAR(300) NOT NULL,
     author_id UUID NOT NULL,
-    likes INT DEFAULT 0,
     createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    search_vector TSVECTOR GENERATED ALWAYS AS (
+        setweight(to_tsvector('simple', title), 'A') ||
+        setweight(to_tsvector('simple', content), 'B')
+    ) STORED,
+
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX posts_createdat_id_idx ON posts (createdAt DESC, id DESC);
+CREATE INDEX posts_search_vector_idx ON posts USING GIN (search_vector);
+
+CREATE TABLE post_reactions(
+    user_id UUID NOT NULL,
+    post_id UUID NOT NULL,
+    type VARCHAR(10) NOT NULL CHECK (type IN ('like', 'love', 'laugh', 'sad', 'angry')),
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (user_id, post_id),
+
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX post_reactions_post_id_type_idx ON post_reactions (post_id, type);
+
+CREATE TABLE follows(
+    follower_id UUID NOT NULL,
+    followed_id UUID NOT NULL,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+
+    PRIMARY KEY (follower_id, followed_id),
+    CHECK (follower_id <> followed_id),
+
+    FOREIGN KEY (follower_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (followed_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX follows_followed_id_idx ON follows (followed_id);
+
+CREATE TABLE comments(
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    post_id UUID NOT NULL,
+    parent_id UUID,
+    author_id UUID NOT NULL,
+    content VARCHAR(300) NOT NULL,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updatedAt TIMESTAMP,
+
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (parent_id)
+    REFERENCES comments(id)
+    ON DELETE CASCADE,
 
     FOREIGN KEY (author_id)
     REFERENCES users(id)
     ON DELETE CASCADE
-);
\ No newline at end of file
+);
+
+CREATE INDEX comments_post_id_createdat_idx ON comments (post_id, createdAt, id);
+CREATE INDEX comments_parent_id_idx ON comments (parent_id);
+
+CREATE TABLE post_tags(
+    post_id UUID NOT NULL,
+    tag VARCHAR(50) NOT NULL,
+
+    PRIMARY KEY (post_id, tag),
+
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX post_tags_tag_idx ON post_tags (tag);
+
+CREATE TABLE post_mentions(
+    post_id UUID NOT NULL,
+    user_id UUID NOT NULL,
+    position INT NOT NULL,
+    length INT NOT NULL,
+
+    PRIMARY KEY (post_id, position),
+
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX post_mentions_user_id_idx ON post_mentions (user_id);
+
+CREATE TABLE notifications(
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    type VARCHAR(20) NOT NULL,
+    actor_id UUID,
+    post_id UUID,
+    comment_id UUID,
+    reaction VARCHAR(10),
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    readAt TIMESTAMP,
+
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    FOREIGN KEY (comment_id)
+    REFERENCES comments(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX notifications_user_id_createdat_idx ON notifications (user_id, createdAt DESC, id DESC);
+CREATE UNIQUE INDEX notifications_post_once_idx ON notifications (user_id, type, actor_id, post_id)
+    WHERE type IN ('reaction', 'mention');
+CREATE UNIQUE INDEX notifications_follow_once_idx ON notifications (user_id, actor_id)
+    WHERE type = 'follow';
+
+CREATE TABLE refresh_tokens(
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    user_id UUID NOT NULL,
+    family_id UUID NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    expiresAt TIMESTAMP NOT NULL,
+    usedAt TIMESTAMP,
+    revokedAt TIMESTAMP,
+
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE
+);
+
+CREATE INDEX refresh_tokens_user_id_idx ON refresh_tokens (user_id);
+CREATE INDEX refresh_tokens_family_id_idx ON refresh_tokens (family_id);
+
+CREATE TABLE audit_log(
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    actor_id UUID NOT NULL,
+    action VARCHAR(50) NOT NULL,
+    target_type VARCHAR(20) NOT NULL,
+    target_id UUID NOT NULL,
+    details JSONB,
+    createdAt TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX audit_log_createdat_id_idx ON audit_log (createdAt DESC, id DESC);
+CREATE INDEX audit_log_target_id_idx ON audit_log (target_id);
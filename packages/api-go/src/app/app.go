@@ -0,0 +1,71 @@
+package app
+
+import (
+	"database/sql"
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/database"
+	"github.com/otaviopontes/api-go/src/metrics"
+	"github.com/otaviopontes/api-go/src/router"
+	"github.com/redis/go-redis/v9"
+)
+
+// App holds the connections shared by every request, opened once at startup,
+// and the handlers built on top of them.
+type App struct {
+	DB       *sql.DB
+	Redis    *redis.Client
+	Handlers controllers.Handlers
+}
+
+// New loads the token keys, connects to Postgres and Redis and wires the
+// repositories into the handlers.
+func New() (*App, error) {
+	keys, err := authentication.LoadKeySet(config.JWTSigningKeyFile, config.JWTVerificationKeyFiles)
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := authentication.NewTokens(keys, authentication.TokenOptions{
+		Issuer:     config.TokenIssuer,
+		Audience:   config.TokenAudience,
+		TTL:        config.AccessTokenTTL,
+		Leeway:     config.TokenLeeway,
+		Algorithms: config.TokenAlgorithms,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := database.Connect()
+	if err != nil {
+		return nil, err
+	}
+
+	redis, err := database.ConnectRedis()
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	metrics.RegisterDB(db, "postgres")
+
+	return &App{
+		DB:       db,
+		Redis:    redis,
+		Handlers: controllers.NewHandlers(db, redis, keys, tokens),
+	}, nil
+}
+
+// Router returns the routes bound to the handlers of the app.
+func (app *App) Router() http.Handler {
+	return router.Generate(app.Handlers)
+}
+
+// Close releases the connections of the app.
+func (app *App) Close() {
+	app.Redis.Close()
+	app.DB.Close()
+}
@@ -1,72 +1,114 @@
 package authentication
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
-	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
-	jwt "github.com/dgrijalva/jwt-go"
 	"github.com/google/uuid"
-	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/models"
 )
 
-func CreateToken(userId uuid.UUID) (string, error) {
-	permissions := jwt.MapClaims{}
-	permissions["authorized"] = true
-	permissions["exp"] = time.Now().Add(time.Hour * 6).UnixMilli()
-	permissions["userId"] = userId
+// NewRefreshToken returns a random refresh token and the hash it is stored by.
+func NewRefreshToken() (string, string, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", "", err
+	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissions)
-	return token.SignedString([]byte(config.SecretKey))
+	token := base64.RawURLEncoding.EncodeToString(raw)
+	return token, HashRefreshToken(token), nil
 }
 
-func ValidateToken(r *http.Request) error {
-	tokenString := extractToken(r)
+// HashRefreshToken returns the hex SHA-256 of token. Refresh tokens are random
+// enough for a plain hash, unlike passwords.
+func HashRefreshToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
 
-	token, err := jwt.Parse(tokenString, returnVerificationKey)
-	if err != nil {
-		return err
-	}
+// Principal is the user an authenticated request is made by, and the access
+// token it is made with.
+type Principal struct {
+	UserId    uuid.UUID
+	Role      models.Role
+	TokenId   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return nil
-	}
+var ErrUnauthenticated = errors.New("the request is not authenticated")
 
-	return errors.New("invalid token")
+type principalKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying principal.
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFrom returns the principal stored in ctx by the authentication
+// middleware.
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(Principal)
+	return principal, ok
 }
 
-func extractToken(r *http.Request) string {
+// UserIdFrom returns the id of the user the request of ctx is authenticated
+// as, or ErrUnauthenticated on routes that do not require authentication.
+func UserIdFrom(ctx context.Context) (uuid.UUID, error) {
+	principal, ok := PrincipalFrom(ctx)
+	if !ok {
+		return uuid.Nil, ErrUnauthenticated
+	}
+	return principal.UserId, nil
+}
+
+// WebSocketProtocol is the subprotocol a browser offers, followed by the token,
+// to authenticate a WebSocket handshake, since it cannot set headers on it.
+const WebSocketProtocol = "bearer"
+
+// BearerToken returns the access token r is made with, from the Authorization
+// header or, for live requests only, from where browsers can send it.
+func BearerToken(r *http.Request) string {
 	token := r.Header.Get("Authorization")
 
 	if len(strings.Split(token, " ")) == 2 {
 		return strings.Split(token, " ")[1]
 	}
-	return ""
-}
 
-func returnVerificationKey(token *jwt.Token) (interface{}, error) {
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	if isLiveRequest(r) {
+		return extractLiveToken(r)
 	}
-	return config.SecretKey, nil
+	return ""
 }
 
-func ExtractUserId(r *http.Request) (uuid.UUID, error) {
-	tokenString := extractToken(r)
+// isLiveRequest reports whether r opens a WebSocket or an event stream, whose
+// browser APIs cannot set the Authorization header.
+func isLiveRequest(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "websocket") ||
+		strings.Contains(r.Header.Get("Accept"), "text/event-stream")
+}
 
-	token, err := jwt.Parse(tokenString, returnVerificationKey)
-	if err != nil {
-		return uuid.Nil, err
+// extractLiveToken reads the token of a live request from the ?access_token=
+// query parameter or from the WebSocket subprotocol following
+// WebSocketProtocol. Other requests never take the token from the URL, where
+// it would end up in access logs.
+func extractLiveToken(r *http.Request) string {
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token
 	}
 
-	if permissions, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		userId, err := uuid.Parse(fmt.Sprintf("%s", permissions["userId"]))
-		if err != nil {
-			return uuid.Nil, err
+	protocols := strings.Split(r.Header.Get("Sec-WebSocket-Protocol"), ",")
+	for i := 0; i+1 < len(protocols); i++ {
+		if strings.TrimSpace(protocols[i]) == WebSocketProtocol {
+			return strings.TrimSpace(protocols[i+1])
 		}
-		return userId, nil
 	}
-	return uuid.Nil, errors.New("invalid token")
+	return ""
 }
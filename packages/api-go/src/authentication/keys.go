@@ -0,0 +1,235 @@
+package authentication
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// minRSABits is the smallest RSA modulus accepted for signing or verifying.
+const minRSABits = 2048
+
+// JWK is a public key in the JSON Web Key format (RFC 7517).
+type JWK struct {
+	KeyType   string `json:"kty"`
+	Id        string `json:"kid"`
+	Use       string `json:"use"`
+	Algorithm string `json:"alg"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+// JWKS is the document other services fetch the verification keys from.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// verificationKey is a public key tokens are accepted from, along with the
+// only signing method it verifies.
+type verificationKey struct {
+	jwk    JWK
+	method jwt.SigningMethod
+	public crypto.PublicKey
+}
+
+// KeySet holds the private key new tokens are signed with and every public key
+// tokens are still accepted from, identified by the kid header of the tokens.
+// To rotate keys, the previous public key stays in the set until the last
+// token it signed has expired, so nobody gets signed out.
+type KeySet struct {
+	signer crypto.Signer
+	// verification starts with the public key of signer.
+	verification []verificationKey
+}
+
+// NewKeySet signs with signer, an RSA (RS256) or Ed25519 (EdDSA) private key,
+// and also verifies with the previous public keys.
+func NewKeySet(signer crypto.Signer, previous ...crypto.PublicKey) (*KeySet, error) {
+	set := &KeySet{signer: signer}
+
+	for _, public := range append([]crypto.PublicKey{signer.Public()}, previous...) {
+		key, err := newVerificationKey(public)
+		if err != nil {
+			return nil, err
+		}
+		if _, found := set.lookup(key.jwk.Id); found {
+			continue
+		}
+		set.verification = append(set.verification, key)
+	}
+
+	return set, nil
+}
+
+// LoadKeySet reads the PEM encoded private key new tokens are signed with from
+// signingKeyFile, and the public keys of the previous signing keys from
+// verificationKeyFiles.
+func LoadKeySet(signingKeyFile string, verificationKeyFiles []string) (*KeySet, error) {
+	if signingKeyFile == "" {
+		return nil, errors.New("the signing key file is not configured")
+	}
+
+	block, err := readPEM(signingKeyFile)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := parsePrivateKey(block)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", signingKeyFile, err)
+	}
+
+	previous := make([]crypto.PublicKey, 0, len(verificationKeyFiles))
+	for _, file := range verificationKeyFiles {
+		block, err := readPEM(file)
+		if err != nil {
+			return nil, err
+		}
+		public, err := parsePublicKey(block)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		previous = append(previous, public)
+	}
+
+	return NewKeySet(signer, previous...)
+}
+
+// JWKS returns the public keys of the set, the signing one first.
+func (set *KeySet) JWKS() JWKS {
+	jwks := JWKS{Keys: make([]JWK, 0, len(set.verification))}
+	for _, key := range set.verification {
+		jwks.Keys = append(jwks.Keys, key.jwk)
+	}
+	return jwks
+}
+
+func (set *KeySet) sign(claims jwt.Claims) (string, error) {
+	signing := set.verification[0]
+
+	token := jwt.NewWithClaims(signing.method, claims)
+	token.Header["kid"] = signing.jwk.Id
+	return token.SignedString(set.signer)
+}
+
+// verificationKey returns the public key named by the kid header of token, as
+// long as token is signed with the method of that key.
+func (set *KeySet) verificationKey(token *jwt.Token) (interface{}, error) {
+	id, _ := token.Header["kid"].(string)
+	key, found := set.lookup(id)
+	if !found {
+		return nil, fmt.Errorf("unknown signing key %q", id)
+	}
+	if token.Method.Alg() != key.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method %v", token.Header["alg"])
+	}
+	return key.public, nil
+}
+
+func (set *KeySet) lookup(id string) (verificationKey, bool) {
+	for _, key := range set.verification {
+		if key.jwk.Id == id {
+			return key, true
+		}
+	}
+	return verificationKey{}, false
+}
+
+func newVerificationKey(public crypto.PublicKey) (verificationKey, error) {
+	encode := base64.RawURLEncoding.EncodeToString
+
+	var key verificationKey
+	var members string
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		if public.N.BitLen() < minRSABits {
+			return key, fmt.Errorf("RSA keys must have at least %d bits", minRSABits)
+		}
+		key.method = jwt.SigningMethodRS256
+		key.jwk = JWK{
+			KeyType: "RSA",
+			N:       encode(public.N.Bytes()),
+			E:       encode(big.NewInt(int64(public.E)).Bytes()),
+		}
+		members = fmt.Sprintf(`{"e":"%s","kty":"RSA","n":"%s"}`, key.jwk.E, key.jwk.N)
+
+	case ed25519.PublicKey:
+		key.method = jwt.SigningMethodEdDSA
+		key.jwk = JWK{
+			KeyType: "OKP",
+			Curve:   "Ed25519",
+			X:       encode(public),
+		}
+		members = fmt.Sprintf(`{"crv":"Ed25519","kty":"OKP","x":"%s"}`, key.jwk.X)
+
+	default:
+		return key, fmt.Errorf("unsupported key type %T, use RSA or Ed25519", public)
+	}
+
+	// The kid is the JWK thumbprint (RFC 7638), so the same key always gets
+	// the same id without configuring one.
+	thumbprint := sha256.Sum256([]byte(members))
+	key.jwk.Id = encode(thumbprint[:])
+	key.jwk.Use = "sig"
+	key.jwk.Algorithm = key.method.Alg()
+	key.public = public
+
+	return key, nil
+}
+
+func readPEM(file string) (*pem.Block, error) {
+	raw, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", file)
+	}
+	return block, nil
+}
+
+func parsePrivateKey(block *pem.Block) (crypto.Signer, error) {
+	var private interface{}
+	var err error
+	switch block.Type {
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q for a private key", block.Type)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key type %T", private)
+	}
+	return signer, nil
+}
+
+func parsePublicKey(block *pem.Block) (crypto.PublicKey, error) {
+	switch block.Type {
+	case "PUBLIC KEY":
+		return x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		return x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unexpected PEM block %q for a public key", block.Type)
+	}
+}
@@ -0,0 +1,108 @@
+package authentication_test
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTokens(t *testing.T, signer crypto.Signer, previous ...crypto.PublicKey) (*authentication.Tokens, *authentication.KeySet) {
+	keys, err := authentication.NewKeySet(signer, previous...)
+	assert.NoError(t, err)
+
+	tokens, err := authentication.NewTokens(keys, authentication.TokenOptions{
+		Issuer:     "test-issuer",
+		Audience:   "test-audience",
+		TTL:        time.Minute,
+		Leeway:     5 * time.Second,
+		Algorithms: []string{"EdDSA", "RS256"},
+	})
+	assert.NoError(t, err)
+	return tokens, keys
+}
+
+func TestTokensSignedBeforeARotationStayValid(t *testing.T) {
+	_, previous, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	userId := uuid.New()
+
+	oldTokens, _ := newTokens(t, previous)
+	oldToken, err := oldTokens.Issue(userId, models.RoleUser)
+	assert.NoError(t, err)
+
+	currentTokens, keys := newTokens(t, current, previous.Public())
+	newToken, err := currentTokens.Issue(userId, models.RoleUser)
+	assert.NoError(t, err)
+
+	for _, token := range []string{oldToken, newToken} {
+		principal, err := currentTokens.Verify(token)
+		assert.NoError(t, err)
+		assert.Equal(t, userId, principal.UserId)
+	}
+
+	jwks := keys.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "RS256", jwks.Keys[0].Algorithm)
+	assert.Equal(t, "EdDSA", jwks.Keys[1].Algorithm)
+
+	// Once the previous key leaves the set, its tokens are refused.
+	onlyCurrent, _ := newTokens(t, current)
+	_, err = onlyCurrent.Verify(oldToken)
+	assert.Error(t, err)
+}
+
+func TestKeysOutsideTheAllowedAlgorithmsAreRefused(t *testing.T) {
+	current, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keys, err := authentication.NewKeySet(current)
+	assert.NoError(t, err)
+
+	_, err = authentication.NewTokens(keys, authentication.TokenOptions{Algorithms: []string{"EdDSA"}})
+	assert.Error(t, err)
+}
+
+func TestLoadKeySet(t *testing.T) {
+	dir := t.TempDir()
+
+	_, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	signing, err := x509.MarshalPKCS8PrivateKey(private)
+	assert.NoError(t, err)
+
+	previous, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	verification, err := x509.MarshalPKIXPublicKey(&previous.PublicKey)
+	assert.NoError(t, err)
+
+	signingFile := filepath.Join(dir, "signing.pem")
+	verificationFile := filepath.Join(dir, "previous.pem")
+	assert.NoError(t, os.WriteFile(signingFile, pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: signing}), 0o600))
+	assert.NoError(t, os.WriteFile(verificationFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: verification}), 0o644))
+
+	keys, err := authentication.LoadKeySet(signingFile, []string{verificationFile})
+	assert.NoError(t, err)
+
+	jwks := keys.JWKS()
+	assert.Len(t, jwks.Keys, 2)
+	assert.Equal(t, "OKP", jwks.Keys[0].KeyType)
+	assert.Equal(t, "RSA", jwks.Keys[1].KeyType)
+	assert.NotEqual(t, jwks.Keys[0].Id, jwks.Keys[1].Id)
+
+	_, err = authentication.LoadKeySet(verificationFile, nil)
+	assert.Error(t, err)
+}
@@ -0,0 +1,141 @@
+package authentication
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+// TokenIssuer issues the access tokens of the users.
+type TokenIssuer interface {
+	Issue(userId uuid.UUID, role models.Role) (string, error)
+}
+
+// TokenVerifier verifies access tokens and returns who they were issued to.
+type TokenVerifier interface {
+	Verify(token string) (Principal, error)
+}
+
+// Claims are the claims of an access token. The subject is the id of the user
+// the token was issued to, and Role their role when it was issued.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role models.Role `json:"role,omitempty"`
+}
+
+// Validate checks that the token carries the claims it can be revoked by, on
+// top of the checks of the parser. Tokens without an id were issued before
+// they could be revoked.
+func (claims Claims) Validate() error {
+	if claims.ID == "" || claims.IssuedAt == nil {
+		return errors.New("the token cannot be revoked, sign in again")
+	}
+	return nil
+}
+
+type TokenOptions struct {
+	// Issuer and Audience are the iss and aud claims of the issued tokens,
+	// which only the tokens carrying both are accepted with.
+	Issuer   string
+	Audience string
+	// TTL is how long the issued tokens are valid for.
+	TTL time.Duration
+	// Leeway is the clock skew tolerated when checking exp, nbf and iat
+	// against the time of this server.
+	Leeway time.Duration
+	// Algorithms are the only signing algorithms tokens are accepted with.
+	Algorithms []string
+}
+
+// Tokens issues access tokens signed with a key set and verifies them against
+// it.
+type Tokens struct {
+	keys    *KeySet
+	options TokenOptions
+	parser  *jwt.Parser
+}
+
+// NewTokens issues and verifies tokens with keys. Every key of the set must use
+// one of options.Algorithms, so no key is loaded only to refuse its tokens.
+func NewTokens(keys *KeySet, options TokenOptions) (*Tokens, error) {
+	for _, key := range keys.verification {
+		if !contains(options.Algorithms, key.method.Alg()) {
+			return nil, fmt.Errorf("the key %s uses %s, which is not an allowed algorithm", key.jwk.Id, key.method.Alg())
+		}
+	}
+
+	return &Tokens{
+		keys:    keys,
+		options: options,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods(options.Algorithms),
+			jwt.WithIssuer(options.Issuer),
+			jwt.WithAudience(options.Audience),
+			jwt.WithLeeway(options.Leeway),
+			jwt.WithExpirationRequired(),
+			jwt.WithIssuedAt(),
+		),
+	}, nil
+}
+
+// Issue returns an access token for userId, granting role, valid for
+// options.TTL. Its jti claim identifies it so it can be revoked.
+func (tokens *Tokens) Issue(userId uuid.UUID, role models.Role) (string, error) {
+	now := time.Now()
+	return tokens.keys.sign(Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userId.String(),
+			Issuer:    tokens.options.Issuer,
+			Audience:  jwt.ClaimStrings{tokens.options.Audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(tokens.options.TTL)),
+		},
+		Role: role,
+	})
+}
+
+// Verify checks the signature and the claims of token and returns its
+// principal.
+func (tokens *Tokens) Verify(token string) (Principal, error) {
+	var claims Claims
+	if _, err := tokens.parser.ParseWithClaims(token, &claims, tokens.keys.verificationKey); err != nil {
+		return Principal{}, err
+	}
+
+	userId, err := uuid.Parse(claims.Subject)
+	if err != nil {
+		return Principal{}, err
+	}
+
+	// Tokens issued before roles existed grant no role beyond user.
+	if claims.Role == "" {
+		claims.Role = models.RoleUser
+	}
+	role, err := models.ParseRole(string(claims.Role))
+	if err != nil {
+		return Principal{}, err
+	}
+
+	return Principal{
+		UserId:    userId,
+		Role:      role,
+		TokenId:   claims.ID,
+		IssuedAt:  claims.IssuedAt.Time,
+		ExpiresAt: claims.ExpiresAt.Time,
+	}, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,117 @@
+package authentication_test
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	tokens, keys := newTokens(t, private)
+	kid := keys.JWKS().Keys[0].Id
+
+	// An RSA key of the set would be allowed, but not in place of the EdDSA
+	// key the kid names.
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+
+	userId := uuid.New()
+	claims := func(change func(*authentication.Claims)) authentication.Claims {
+		now := time.Now()
+		c := authentication.Claims{RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.NewString(),
+			Subject:   userId.String(),
+			Issuer:    "test-issuer",
+			Audience:  jwt.ClaimStrings{"test-audience"},
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute)),
+		}}
+		if change != nil {
+			change(&c)
+		}
+		return c
+	}
+	sign := func(method jwt.SigningMethod, key interface{}, claims authentication.Claims) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		assert.NoError(t, err)
+		return signed
+	}
+	tamper := func(token string) string {
+		parts := strings.Split(token, ".")
+		payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+		assert.NoError(t, err)
+		parts[1] = base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(payload), userId.String(), uuid.NewString(), 1)))
+		return strings.Join(parts, ".")
+	}
+	issued, err := tokens.Issue(userId, models.RoleUser)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"issued", issued, true},
+		{"signed", sign(jwt.SigningMethodEdDSA, private, claims(nil)), true},
+		{"expired", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+		})), false},
+		{"expired within the leeway", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-2 * time.Second))
+		})), true},
+		{"without expiration", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.ExpiresAt = nil
+		})), false},
+		{"not yet valid", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(time.Minute))
+		})), false},
+		{"not yet valid within the leeway", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.NotBefore = jwt.NewNumericDate(time.Now().Add(2 * time.Second))
+			c.IssuedAt = c.NotBefore
+		})), true},
+		{"issued in the future", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.IssuedAt = jwt.NewNumericDate(time.Now().Add(time.Minute))
+		})), false},
+		{"without id", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.ID = ""
+		})), false},
+		{"other issuer", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.Issuer = "someone-else"
+		})), false},
+		{"other audience", sign(jwt.SigningMethodEdDSA, private, claims(func(c *authentication.Claims) {
+			c.Audience = jwt.ClaimStrings{"another-api"}
+		})), false},
+		{"HS256 with the public key as secret", sign(jwt.SigningMethodHS256, []byte(public), claims(nil)), false},
+		{"RS256 in place of EdDSA", sign(jwt.SigningMethodRS256, other, claims(nil)), false},
+		{"unsigned", sign(jwt.SigningMethodNone, jwt.UnsafeAllowNoneSignatureType, claims(nil)), false},
+		{"tampered", tamper(issued), false},
+		{"malformed", "not.a.token", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal, err := tokens.Verify(test.token)
+			if !test.valid {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, userId, principal.UserId)
+		})
+	}
+}
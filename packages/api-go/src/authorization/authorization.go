@@ -0,0 +1,158 @@
+package authorization
+
+import (
+	"context"
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+// Action is an operation on a resource that not every user may perform.
+type Action string
+
+const (
+	UpdatePost     Action = "posts:update"
+	DeletePost     Action = "posts:delete"
+	UpdateUser     Action = "users:update"
+	DeleteUser     Action = "users:delete"
+	UpdatePassword Action = "users:update-password"
+	SuspendUser    Action = "users:suspend"
+	UnsuspendUser  Action = "users:unsuspend"
+	LogoutUser     Action = "users:logout"
+	ResetPassword  Action = "users:reset-password"
+	UpdateComment  Action = "comments:update"
+	DeleteComment  Action = "comments:delete"
+)
+
+// Resource is what an action is performed on, described by its owner: the
+// author of a post or comment, or the user of an account. ParentOwnerId is the
+// owner of what the resource belongs to, such as the author of the post a
+// comment is on.
+type Resource struct {
+	OwnerId       uuid.UUID
+	OwnerRole     models.Role
+	ParentOwnerId uuid.UUID
+}
+
+// Policy grants an action to the owner of the resource, when Owner is set, to
+// the owner of its parent, when ParentOwner is set, and to the users whose role
+// includes Role, when it is set. With OutrankOwner,
+// the role only grants the action on the resources of lower roles, so
+// moderators cannot act on each other.
+type Policy struct {
+	Owner        bool
+	ParentOwner  bool
+	Role         models.Role
+	OutrankOwner bool
+	// Denied is the error the action is refused with.
+	Denied error
+}
+
+var policies = map[Action]Policy{
+	UpdatePost: {
+		Owner:  true,
+		Denied: errors.New("it is not possible to update a post that is not yours"),
+	},
+	DeletePost: {
+		Owner:  true,
+		Role:   models.RoleModerator,
+		Denied: errors.New("it is not possible to delete a post that is not yours"),
+	},
+	UpdateUser: {
+		Owner:  true,
+		Denied: errors.New("it is not possible to update a user if not yours"),
+	},
+	DeleteUser: {
+		Owner:  true,
+		Denied: errors.New("it is not possible to delete a user if not yours"),
+	},
+	UpdatePassword: {
+		Owner:  true,
+		Denied: errors.New("it is not possible to change other user's password"),
+	},
+	SuspendUser: {
+		Role:         models.RoleModerator,
+		OutrankOwner: true,
+		Denied:       errors.New("it is not possible to suspend this user"),
+	},
+	UnsuspendUser: {
+		Role:         models.RoleModerator,
+		OutrankOwner: true,
+		Denied:       errors.New("it is not possible to unsuspend this user"),
+	},
+	LogoutUser: {
+		Role:         models.RoleAdmin,
+		OutrankOwner: true,
+		Denied:       errors.New("it is not possible to sign this user out"),
+	},
+	ResetPassword: {
+		Role:         models.RoleAdmin,
+		OutrankOwner: true,
+		Denied:       errors.New("it is not possible to reset the password of this user"),
+	},
+	UpdateComment: {
+		Owner:  true,
+		Denied: errors.New("it is not possible to update a comment that is not yours"),
+	},
+	DeleteComment: {
+		Owner:       true,
+		ParentOwner: true,
+		Role:        models.RoleModerator,
+		Denied:      errors.New("it is not possible to delete a comment that is not yours"),
+	},
+}
+
+var ErrForbidden = errors.New("you are not allowed to do this")
+
+// Can reports whether principal may perform action on resource. Actions
+// without a policy are denied.
+func Can(principal authentication.Principal, action Action, resource Resource) bool {
+	policy, ok := policies[action]
+	if !ok {
+		return false
+	}
+
+	if policy.Owner && principal.UserId == resource.OwnerId {
+		return true
+	}
+	if policy.ParentOwner && resource.ParentOwnerId != uuid.Nil && principal.UserId == resource.ParentOwnerId {
+		return true
+	}
+	if policy.Role == "" || !principal.Role.Includes(policy.Role) {
+		return false
+	}
+	return !policy.OutrankOwner || principal.Role.Outranks(resource.OwnerRole)
+}
+
+// Authorize returns nil when the principal of ctx may perform action on
+// resource, and the error the action is denied with otherwise.
+func Authorize(ctx context.Context, action Action, resource Resource) error {
+	principal, ok := authentication.PrincipalFrom(ctx)
+	if !ok {
+		return authentication.ErrUnauthenticated
+	}
+
+	if Can(principal, action, resource) {
+		return nil
+	}
+	if policy, ok := policies[action]; ok && policy.Denied != nil {
+		return policy.Denied
+	}
+	return ErrForbidden
+}
+
+// RequireRole returns nil when the principal of ctx has a role including
+// role.
+func RequireRole(ctx context.Context, role models.Role) error {
+	principal, ok := authentication.PrincipalFrom(ctx)
+	if !ok {
+		return authentication.ErrUnauthenticated
+	}
+
+	if !principal.Role.Includes(role) {
+		return ErrForbidden
+	}
+	return nil
+}
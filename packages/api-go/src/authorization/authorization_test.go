@@ -0,0 +1,52 @@
+package authorization_test
+
+import (
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCan(t *testing.T) {
+	userId := uuid.New()
+	other := uuid.New()
+
+	tests := []struct {
+		name     string
+		role     models.Role
+		action   authorization.Action
+		resource authorization.Resource
+		allowed  bool
+	}{
+		{"owner updates their post", models.RoleUser, authorization.UpdatePost, authorization.Resource{OwnerId: userId}, true},
+		{"user updates another post", models.RoleUser, authorization.UpdatePost, authorization.Resource{OwnerId: other}, false},
+		{"moderator updates another post", models.RoleModerator, authorization.UpdatePost, authorization.Resource{OwnerId: other}, false},
+		{"user deletes another post", models.RoleUser, authorization.DeletePost, authorization.Resource{OwnerId: other}, false},
+		{"moderator deletes another post", models.RoleModerator, authorization.DeletePost, authorization.Resource{OwnerId: other}, true},
+		{"admin deletes another post", models.RoleAdmin, authorization.DeletePost, authorization.Resource{OwnerId: other}, true},
+		{"user suspends a user", models.RoleUser, authorization.SuspendUser, authorization.Resource{OwnerId: other, OwnerRole: models.RoleUser}, false},
+		{"moderator suspends a user", models.RoleModerator, authorization.SuspendUser, authorization.Resource{OwnerId: other, OwnerRole: models.RoleUser}, true},
+		{"moderator suspends a moderator", models.RoleModerator, authorization.SuspendUser, authorization.Resource{OwnerId: other, OwnerRole: models.RoleModerator}, false},
+		{"moderator suspends themselves", models.RoleModerator, authorization.SuspendUser, authorization.Resource{OwnerId: userId, OwnerRole: models.RoleModerator}, false},
+		{"admin suspends a moderator", models.RoleAdmin, authorization.SuspendUser, authorization.Resource{OwnerId: other, OwnerRole: models.RoleModerator}, true},
+		{"admin changes another password", models.RoleAdmin, authorization.UpdatePassword, authorization.Resource{OwnerId: other}, false},
+		{"owner updates their comment", models.RoleUser, authorization.UpdateComment, authorization.Resource{OwnerId: userId, ParentOwnerId: other}, true},
+		{"post author updates a comment", models.RoleUser, authorization.UpdateComment, authorization.Resource{OwnerId: other, ParentOwnerId: userId}, false},
+		{"moderator updates a comment", models.RoleModerator, authorization.UpdateComment, authorization.Resource{OwnerId: other, ParentOwnerId: other}, false},
+		{"owner deletes their comment", models.RoleUser, authorization.DeleteComment, authorization.Resource{OwnerId: userId, ParentOwnerId: other}, true},
+		{"post author deletes a comment", models.RoleUser, authorization.DeleteComment, authorization.Resource{OwnerId: other, ParentOwnerId: userId}, true},
+		{"user deletes another comment", models.RoleUser, authorization.DeleteComment, authorization.Resource{OwnerId: other, ParentOwnerId: other}, false},
+		{"moderator deletes a comment", models.RoleModerator, authorization.DeleteComment, authorization.Resource{OwnerId: other, ParentOwnerId: other}, true},
+		{"action without policy", models.RoleAdmin, authorization.Action("posts:pin"), authorization.Resource{OwnerId: userId}, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			principal := authentication.Principal{UserId: userId, Role: test.role}
+			assert.Equal(t, test.allowed, authorization.Can(principal, test.action, test.resource))
+		})
+	}
+}
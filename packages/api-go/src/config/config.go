@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -16,8 +18,43 @@ var (
 	RedisAddr       = ""
 	RedisPassword   = ""
 	RedisDb         = 0
+	FanoutWorkers   = 0
+	TimelineSize    = 0
+	TrendingWindow  = 0
+	EventStreamSize = 0
+	DBMaxOpenConns  = 0
+	DBMaxIdleConns  = 0
+	DBConnLifetime  = 0
+	RequestTimeout  = 0
+	ReadTimeout     = 0
+	WriteTimeout    = 0
+	IdleTimeout     = 0
+	MaxHeaderBytes  = 0
+	ShutdownTimeout = 0
+	LogLevel        = ""
+	AccessTokenTTL  = 15 * time.Minute
+	RefreshTokenTTL = 30 * 24 * time.Hour
+	// TokenIssuer and TokenAudience are the iss and aud claims of the access
+	// tokens, which only the tokens carrying both are accepted with.
+	TokenIssuer   = "api-dialog"
+	TokenAudience = "api-dialog"
+	// TokenLeeway is the clock skew tolerated on the time claims of the
+	// tokens, and TokenAlgorithms the signing algorithms they may use.
+	TokenLeeway     = 30 * time.Second
+	TokenAlgorithms = []string{"EdDSA", "RS256"}
+
+	TracingExporter    = ""
+	TracingEndpoint    = ""
+	TracingInsecure    = false
+	TracingFile        = ""
+	TracingSampleRatio = 0.0
+
+	// JWTSigningKeyFile holds the PEM private key access tokens are signed
+	// with, and JWTVerificationKeyFiles the PEM public keys of the previous
+	// signing keys, still accepted while their tokens have not expired.
+	JWTSigningKeyFile       = ""
+	JWTVerificationKeyFiles []string
 )
-var SecretKey []byte
 
 func Load() {
 	var err error
@@ -41,6 +78,120 @@ func Load() {
 
 	FrontEndUrl = os.Getenv("FRONTEND_URL")
 
+	LogLevel = os.Getenv("LOG_LEVEL")
+
+	accessTokenMinutes, err := strconv.Atoi(os.Getenv("ACCESS_TOKEN_TTL_MINUTES"))
+	if err != nil {
+		accessTokenMinutes = 15
+	}
+	AccessTokenTTL = time.Duration(accessTokenMinutes) * time.Minute
+
+	refreshTokenHours, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_TTL_HOURS"))
+	if err != nil {
+		refreshTokenHours = 30 * 24
+	}
+	RefreshTokenTTL = time.Duration(refreshTokenHours) * time.Hour
+
+	if issuer := os.Getenv("JWT_ISSUER"); issuer != "" {
+		TokenIssuer = issuer
+	}
+	if audience := os.Getenv("JWT_AUDIENCE"); audience != "" {
+		TokenAudience = audience
+	}
+
+	leewaySeconds, err := strconv.Atoi(os.Getenv("JWT_LEEWAY_SECONDS"))
+	if err != nil {
+		leewaySeconds = 30
+	}
+	TokenLeeway = time.Duration(leewaySeconds) * time.Second
+
+	if algorithms := splitList(os.Getenv("JWT_ALGORITHMS")); len(algorithms) > 0 {
+		TokenAlgorithms = algorithms
+	}
+
+	TracingExporter = os.Getenv("TRACING_EXPORTER")
+	TracingEndpoint = os.Getenv("TRACING_ENDPOINT")
+
+	TracingInsecure, err = strconv.ParseBool(os.Getenv("TRACING_INSECURE"))
+	if err != nil {
+		TracingInsecure = false
+	}
+
+	TracingFile = os.Getenv("TRACING_FILE")
+	if TracingFile == "" {
+		TracingFile = "traces.json"
+	}
+
+	TracingSampleRatio, err = strconv.ParseFloat(os.Getenv("TRACING_SAMPLE_RATIO"), 64)
+	if err != nil {
+		TracingSampleRatio = 1
+	}
+
+	FanoutWorkers, err = strconv.Atoi(os.Getenv("TIMELINE_FANOUT_WORKERS"))
+	if err != nil {
+		FanoutWorkers = 4
+	}
+
+	TimelineSize, err = strconv.Atoi(os.Getenv("TIMELINE_SIZE"))
+	if err != nil {
+		TimelineSize = 800
+	}
+
+	TrendingWindow, err = strconv.Atoi(os.Getenv("TRENDING_WINDOW_HOURS"))
+	if err != nil {
+		TrendingWindow = 24
+	}
+
+	EventStreamSize, err = strconv.Atoi(os.Getenv("EVENT_STREAM_SIZE"))
+	if err != nil {
+		EventStreamSize = 1000
+	}
+
+	DBMaxOpenConns, err = strconv.Atoi(os.Getenv("DB_MAX_OPEN_CONNS"))
+	if err != nil {
+		DBMaxOpenConns = 25
+	}
+
+	DBMaxIdleConns, err = strconv.Atoi(os.Getenv("DB_MAX_IDLE_CONNS"))
+	if err != nil {
+		DBMaxIdleConns = 25
+	}
+
+	DBConnLifetime, err = strconv.Atoi(os.Getenv("DB_CONN_MAX_LIFETIME_MINUTES"))
+	if err != nil {
+		DBConnLifetime = 5
+	}
+
+	RequestTimeout, err = strconv.Atoi(os.Getenv("REQUEST_TIMEOUT_SECONDS"))
+	if err != nil {
+		RequestTimeout = 10
+	}
+
+	ReadTimeout, err = strconv.Atoi(os.Getenv("HTTP_READ_TIMEOUT_SECONDS"))
+	if err != nil {
+		ReadTimeout = 15
+	}
+
+	WriteTimeout, err = strconv.Atoi(os.Getenv("HTTP_WRITE_TIMEOUT_SECONDS"))
+	if err != nil {
+		WriteTimeout = 30
+	}
+
+	IdleTimeout, err = strconv.Atoi(os.Getenv("HTTP_IDLE_TIMEOUT_SECONDS"))
+	if err != nil {
+		IdleTimeout = 120
+	}
+
+	MaxHeaderBytes, err = strconv.Atoi(os.Getenv("HTTP_MAX_HEADER_BYTES"))
+	if err != nil {
+		MaxHeaderBytes = 1 << 20
+	}
+
+	ShutdownTimeout, err = strconv.Atoi(os.Getenv("SHUTDOWN_TIMEOUT_SECONDS"))
+	if err != nil {
+		ShutdownTimeout = 20
+	}
+
 	ConectionString = fmt.Sprintf(
 		"user=%s dbname=%s sslmode=disable password=%s host=%s port=%s",
 
@@ -51,7 +202,8 @@ func Load() {
 		os.Getenv("DB_PORT"),
 	)
 
-	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	JWTSigningKeyFile = os.Getenv("JWT_SIGNING_KEY_FILE")
+	JWTVerificationKeyFiles = splitList(os.Getenv("JWT_VERIFICATION_KEY_FILES"))
 }
 
 func LoadTest() {
@@ -78,5 +230,17 @@ func LoadTest() {
 		os.Getenv("DB_PORT"),
 	)
 
-	SecretKey = []byte(os.Getenv("SECRET_KEY"))
+	JWTSigningKeyFile = os.Getenv("JWT_SIGNING_KEY_FILE")
+	JWTVerificationKeyFiles = splitList(os.Getenv("JWT_VERIFICATION_KEY_FILES"))
+}
+
+// splitList returns the comma separated items of value, skipping empty ones.
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
 }
@@ -0,0 +1,358 @@
+package controllers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/realtime"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+	"github.com/otaviopontes/api-go/src/security"
+)
+
+// AdminHandler serves the /api/admin routes, and the suspension routes under
+// /api/users moderators use. Every change it makes is recorded to the audit
+// trail.
+type AdminHandler struct {
+	users    repositories.UserRepository
+	posts    repositories.PostRepository
+	sessions repositories.SessionRepository
+	audit    repositories.AuditRepository
+	stats    repositories.StatsRepository
+}
+
+func NewAdminHandler(
+	users repositories.UserRepository,
+	posts repositories.PostRepository,
+	sessions repositories.SessionRepository,
+	audit repositories.AuditRepository,
+	stats repositories.StatsRepository,
+) *AdminHandler {
+	return &AdminHandler{users, posts, sessions, audit, stats}
+}
+
+type passwordResetResponse struct {
+	Password string `json:"password"`
+}
+
+// @Summary      List users
+// @Description  Lists the users matching the filters, newest first, with their role and suspension. Pass the returned nextCursor as cursor to fetch the following page.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        q          query     string  false  "Text found in the nick, name or email"
+// @Param        role       query     string  false  "Only list the users of this role"  Enums(user, moderator, admin)
+// @Param        suspended  query     bool    false  "Only list the suspended, or the active, users"
+// @Param        limit      query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor     query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.UserPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/users [get]
+func (handler *AdminHandler) ListUsers(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := models.UserFilter{Query: strings.TrimSpace(query.Get("q"))}
+
+	if role := query.Get("role"); role != "" {
+		parsed, err := models.ParseRole(role)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.Role = parsed
+	}
+
+	if suspended := query.Get("suspended"); suspended != "" {
+		parsed, err := strconv.ParseBool(suspended)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, errors.New("suspended must be true or false"))
+			return
+		}
+		filter.Suspended = &parsed
+	}
+
+	cursor, limit, err := parsePagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := handler.users.List(r.Context(), filter, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, page)
+}
+
+// @Summary      Suspend a user
+// @Description  Keeps a user from signing in and signs them out of every session. Moderators and admins can only suspend the users of a lower role.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/users/{id}/suspension [post]
+// @Router       /users/{id}/suspension [post]
+func (handler *AdminHandler) SuspendUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := moderatedUser(w, r, handler.users, authorization.SuspendUser)
+	if !ok {
+		return
+	}
+
+	suspend := func(ctx context.Context) error {
+		if err := handler.users.Suspend(ctx, user.Id); err != nil {
+			return err
+		}
+		return handler.sessions.RevokeUser(ctx, user.Id)
+	}
+	if !audited(w, r, handler.audit, authorization.SuspendUser, models.AuditTargetUser, user.Id, nil, suspend) {
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Unsuspend a user
+// @Description  Lets a suspended user sign in again. Moderators and admins can only unsuspend the users of a lower role.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/users/{id}/suspension [delete]
+// @Router       /users/{id}/suspension [delete]
+func (handler *AdminHandler) UnsuspendUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := moderatedUser(w, r, handler.users, authorization.UnsuspendUser)
+	if !ok {
+		return
+	}
+
+	unsuspend := func(ctx context.Context) error {
+		return handler.users.Unsuspend(ctx, user.Id)
+	}
+	if !audited(w, r, handler.audit, authorization.UnsuspendUser, models.AuditTargetUser, user.Id, nil, unsuspend) {
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Sign a user out
+// @Description  Revokes every access and refresh token of a user, who stays able to sign in again.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/users/{id}/logout [post]
+func (handler *AdminHandler) LogoutUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := moderatedUser(w, r, handler.users, authorization.LogoutUser)
+	if !ok {
+		return
+	}
+
+	logout := func(ctx context.Context) error {
+		return handler.sessions.RevokeUser(ctx, user.Id)
+	}
+	if !audited(w, r, handler.audit, authorization.LogoutUser, models.AuditTargetUser, user.Id, nil, logout) {
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Reset the password of a user
+// @Description  Replaces the password of a user with a random one, returned only in this response, and signs them out of every session.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {object}  passwordResetResponse
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/users/{id}/password-reset [post]
+func (handler *AdminHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	user, ok := moderatedUser(w, r, handler.users, authorization.ResetPassword)
+	if !ok {
+		return
+	}
+
+	raw := make([]byte, 12)
+	if _, err := rand.Read(raw); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+	password := base64.RawURLEncoding.EncodeToString(raw)
+
+	hashedPassword, err := security.Hash(password)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	// The entry does not hold the password, which only this response does.
+	reset := func(ctx context.Context) error {
+		if err := handler.users.UpdatePassword(ctx, user.Id, hashedPassword); err != nil {
+			return err
+		}
+		return handler.sessions.RevokeUser(ctx, user.Id)
+	}
+	if !audited(w, r, handler.audit, authorization.ResetPassword, models.AuditTargetUser, user.Id, nil, reset) {
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, passwordResetResponse{Password: password})
+}
+
+// @Summary      Delete any post
+// @Description  Deletes a post whoever wrote it, along with its comments and reactions.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Post ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/posts/{id} [delete]
+func (handler *AdminHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	if !authorize(w, r, authorization.DeletePost, authorization.Resource{OwnerId: post.AuthorId}) {
+		return
+	}
+
+	remove := func(ctx context.Context) error {
+		return handler.posts.Delete(ctx, postId)
+	}
+	if !audited(w, r, handler.audit, authorization.DeletePost, models.AuditTargetPost, postId, deletedPost(post), remove) {
+		return
+	}
+
+	realtime.Publish(realtime.PostDeleted, realtime.PostRef{Id: postId})
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Get the aggregated counts
+// @Description  Counts the users, by role and suspended, the posts, comments, reactions and follows.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  models.Stats
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/stats [get]
+func (handler *AdminHandler) GetStats(w http.ResponseWriter, r *http.Request) {
+	stats, err := handler.stats.Get(r.Context())
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, stats)
+}
+
+// @Summary      Get the audit trail
+// @Description  Lists the actions taken through the admin API and by moderators on the content of others, newest first. Pass the returned nextCursor as cursor to fetch the following page.
+// @Tags         Admin
+// @Accept       json
+// @Produce      json
+// @Param        target  query     string  false  "Only list the actions on this user or post ID"
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.AuditPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /admin/audit [get]
+func (handler *AdminHandler) GetAuditLog(w http.ResponseWriter, r *http.Request) {
+	var targetId uuid.UUID
+	if target := r.URL.Query().Get("target"); target != "" {
+		var err error
+		targetId, err = uuid.Parse(target)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	cursor, limit, err := parsePagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := handler.audit.List(r.Context(), targetId, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, page)
+}
@@ -0,0 +1,244 @@
+package controllers_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/security"
+	"github.com/stretchr/testify/assert"
+)
+
+type adminMocks struct {
+	users    *repositories.MockUserRepository
+	posts    *repositories.MockPostRepository
+	sessions *repositories.MockSessionRepository
+	audit    *repositories.MockAuditRepository
+}
+
+func newAdminHandler(t *testing.T) (*controllers.AdminHandler, adminMocks) {
+	ctrl := gomock.NewController(t)
+	mocks := adminMocks{
+		users:    repositories.NewMockUserRepository(ctrl),
+		posts:    repositories.NewMockPostRepository(ctrl),
+		sessions: repositories.NewMockSessionRepository(ctrl),
+		audit:    repositories.NewMockAuditRepository(ctrl),
+	}
+	handler := controllers.NewAdminHandler(mocks.users, mocks.posts, mocks.sessions, mocks.audit, repositories.NewMockStatsRepository(ctrl))
+	return handler, mocks
+}
+
+// recordAndRun stands for AuditRepository.Record, running the action of the
+// entry after checking it.
+func recordAndRun(check func(models.AuditEntry)) func(context.Context, models.AuditEntry, func(context.Context) error) error {
+	return func(ctx context.Context, entry models.AuditEntry, action func(context.Context) error) error {
+		check(entry)
+		return action(ctx)
+	}
+}
+
+func TestAdminSuspendsUser(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	adminId := uuid.New()
+	userId := uuid.New()
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleModerator}, nil)
+	mocks.users.EXPECT().Suspend(gomock.Any(), userId).Return(nil)
+	mocks.sessions.EXPECT().RevokeUser(gomock.Any(), userId).Return(nil)
+	mocks.audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+			assert.Equal(t, models.AuditEntry{ActorId: adminId, Action: string(authorization.SuspendUser), TargetType: models.AuditTargetUser, TargetId: userId}, entry)
+		}))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/admin/users/"+userId.String()+"/suspension", "", adminId, models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.SuspendUser(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAdminSuspendsAnotherAdmin(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	userId := uuid.New()
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleAdmin}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/admin/users/"+userId.String()+"/suspension", "", uuid.New(), models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.SuspendUser(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestModeratorSuspendsUser(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	moderatorId := uuid.New()
+	userId := uuid.New()
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleUser}, nil)
+	mocks.users.EXPECT().Suspend(gomock.Any(), userId).Return(nil)
+	mocks.sessions.EXPECT().RevokeUser(gomock.Any(), userId).Return(nil)
+	mocks.audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+			assert.Equal(t, models.AuditEntry{ActorId: moderatorId, Action: string(authorization.SuspendUser), TargetType: models.AuditTargetUser, TargetId: userId}, entry)
+		}))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/users/"+userId.String()+"/suspension", "", moderatorId, models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.SuspendUser(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestModeratorSuspendsUserOfTheSameRole(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	userId := uuid.New()
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleModerator}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/users/"+userId.String()+"/suspension", "", uuid.New(), models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.SuspendUser(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestAdminResetsPassword(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	userId := uuid.New()
+	var stored []byte
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleUser}, nil)
+	mocks.users.EXPECT().
+		UpdatePassword(gomock.Any(), userId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, password []byte) error {
+			stored = password
+			return nil
+		})
+	mocks.sessions.EXPECT().RevokeUser(gomock.Any(), userId).Return(nil)
+	mocks.audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+			assert.Equal(t, string(authorization.ResetPassword), entry.Action)
+			assert.Empty(t, entry.Details)
+		}))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/admin/users/"+userId.String()+"/password-reset", "", uuid.New(), models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.ResetPassword(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response struct {
+		Password string `json:"password"`
+	}
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotEmpty(t, response.Password)
+	assert.NoError(t, security.VerifyPassword(response.Password, string(stored)))
+}
+
+func TestAdminDeletesPost(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	postId := uuid.New()
+	authorId := uuid.New()
+	mocks.posts.EXPECT().
+		GetPostById(gomock.Any(), postId).
+		Return(models.Post{Id: postId, AuthorId: authorId, Title: "Spam", Content: "buy now"}, nil)
+	mocks.posts.EXPECT().Delete(gomock.Any(), postId).Return(nil)
+	mocks.audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+			assert.Equal(t, models.AuditTargetPost, entry.TargetType)
+			assert.Equal(t, postId, entry.TargetId)
+			assert.JSONEq(t, `{"authorId": "`+authorId.String()+`", "title": "Spam", "content": "buy now"}`, string(entry.Details))
+		}))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodDelete, "/api/admin/posts/"+postId.String(), "", uuid.New(), models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.DeletePost(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestAdminResetsPasswordWhenAuditFails(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	userId := uuid.New()
+	mocks.users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleUser}, nil)
+	mocks.users.EXPECT().UpdatePassword(gomock.Any(), userId, gomock.Any()).Return(nil)
+	mocks.sessions.EXPECT().RevokeUser(gomock.Any(), userId).Return(nil)
+	// The entry could not be inserted, so the transaction of the reset was
+	// rolled back and the request fails without handing out the password.
+	mocks.audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(ctx context.Context, _ models.AuditEntry, action func(context.Context) error) error {
+			assert.NoError(t, action(ctx))
+			return errors.New("pq: relation \"audit_log\" does not exist")
+		})
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPost, "/api/admin/users/"+userId.String()+"/password-reset", "", uuid.New(), models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.ResetPassword(w, r)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NotContains(t, w.Body.String(), "password\"")
+}
+
+func TestAdminDeletesMissingPost(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	postId := uuid.New()
+	mocks.posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{}, repositories.ErrPostNotFound)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodDelete, "/api/admin/posts/"+postId.String(), "", uuid.New(), models.RoleAdmin)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.DeletePost(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestAdminListsUsersWithFilters(t *testing.T) {
+	handler, mocks := newAdminHandler(t)
+
+	suspended := true
+	mocks.users.EXPECT().
+		List(gomock.Any(), models.UserFilter{Query: "ali", Role: models.RoleModerator, Suspended: &suspended}, models.Cursor{}, 20).
+		Return(models.UserPage{Users: []models.User{}}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodGet, "/api/admin/users?q=ali&role=moderator&suspended=true", "", uuid.New(), models.RoleAdmin)
+	handler.ListUsers(w, r)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestAdminListsUsersOfUnknownRole(t *testing.T) {
+	handler, _ := newAdminHandler(t)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodGet, "/api/admin/users?role=owner", "", uuid.New(), models.RoleAdmin)
+	handler.ListUsers(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
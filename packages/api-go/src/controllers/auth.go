@@ -0,0 +1,229 @@
+package controllers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+	"github.com/otaviopontes/api-go/src/security"
+	_ "github.com/swaggo/http-swagger"
+)
+
+type AuthHandler struct {
+	users    repositories.UserRepository
+	sessions repositories.SessionRepository
+	tokens   authentication.TokenIssuer
+}
+
+func NewAuthHandler(users repositories.UserRepository, sessions repositories.SessionRepository, tokens authentication.TokenIssuer) *AuthHandler {
+	return &AuthHandler{users, sessions, tokens}
+}
+
+type refreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+// @Summary      User Login
+// @Description  Authenticate a user with their email and password. The access token expires after a few minutes; exchange the refresh token at /auth/refresh for a new pair before then.
+// @Tags         Login
+// @Accept       json
+// @Produce      json
+// @Param        email     body  string  true  "User email"
+// @Param        password  body  string  true  "User password"
+// @Success      200   {object}  responses.AuthResponse
+// @Failure      400   {object}  responses.ErrorResponse
+// @Failure      401   {object}  responses.ErrorResponse
+// @Failure      403   {object}  responses.ErrorResponse
+// @Failure      404   {object}  responses.ErrorResponse
+// @Failure      422   {object}  responses.ErrorResponse
+// @Failure      500   {object}  responses.ErrorResponse
+// @Failure      503   {object}  responses.ErrorResponse
+// @Failure      504   {object}  responses.ErrorResponse
+// @Router       /login [post]
+func (handler *AuthHandler) Login(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var user models.User
+	if err = json.Unmarshal(requestBody, &user); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	savedUser, err := handler.users.SearchByEmail(r.Context(), user.Email)
+	if err != nil {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
+
+	if err := security.VerifyPassword(user.Password, savedUser.Password); err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if savedUser.SuspendedAt != nil {
+		responses.Error(w, http.StatusForbidden, errAccountSuspended)
+		return
+	}
+
+	refreshToken, refreshTokenHash, err := authentication.NewRefreshToken()
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	err = handler.sessions.CreateRefreshToken(r.Context(), savedUser.Id, uuid.New(), refreshTokenHash, time.Now().Add(config.RefreshTokenTTL))
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	handler.respondWithTokens(w, r, savedUser, refreshToken)
+}
+
+// @Summary      Refresh the access token
+// @Description  Exchanges a refresh token for a new access token and a new refresh token. Each refresh token works once: presenting one that was already exchanged signs out the session it belongs to.
+// @Tags         Login
+// @Accept       json
+// @Produce      json
+// @Param        body  body      refreshTokenRequest  true  "Refresh token"
+// @Success      200   {object}  responses.AuthResponse
+// @Failure      400   {object}  responses.ErrorResponse
+// @Failure      401   {object}  responses.ErrorResponse
+// @Failure      403   {object}  responses.ErrorResponse
+// @Failure      422   {object}  responses.ErrorResponse
+// @Failure      500   {object}  responses.ErrorResponse
+// @Failure      503   {object}  responses.ErrorResponse
+// @Failure      504   {object}  responses.ErrorResponse
+// @Router       /auth/refresh [post]
+func (handler *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body refreshTokenRequest
+	if err = json.Unmarshal(requestBody, &body); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+	if body.RefreshToken == "" {
+		responses.Error(w, http.StatusBadRequest, errors.New("the refresh token is mandatory"))
+		return
+	}
+
+	refreshToken, refreshTokenHash, err := authentication.NewRefreshToken()
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	userId, err := handler.sessions.RotateRefreshToken(
+		r.Context(),
+		authentication.HashRefreshToken(body.RefreshToken),
+		refreshTokenHash,
+		time.Now().Add(config.RefreshTokenTTL),
+	)
+	if errors.Is(err, repositories.ErrInvalidRefreshToken) || errors.Is(err, repositories.ErrRefreshTokenReused) {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	// The role is read again, so a new one applies from the next refresh.
+	user, err := handler.users.GetById(r.Context(), userId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	if user.SuspendedAt != nil {
+		responses.Error(w, http.StatusForbidden, errAccountSuspended)
+		return
+	}
+
+	handler.respondWithTokens(w, r, user, refreshToken)
+}
+
+// @Summary      Log out
+// @Description  Revokes the access token of the request and, when given, the refresh token of the session.
+// @Tags         Login
+// @Accept       json
+// @Param        body  body  refreshTokenRequest  false  "Refresh token of the session"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      422  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /auth/logout [post]
+func (handler *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	principal, ok := authentication.PrincipalFrom(r.Context())
+	if !ok {
+		responses.Error(w, http.StatusUnauthorized, authentication.ErrUnauthenticated)
+		return
+	}
+
+	requestBody, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	// The body is optional: without it only the access token is revoked.
+	var body refreshTokenRequest
+	if len(requestBody) > 0 {
+		if err = json.Unmarshal(requestBody, &body); err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err := handler.sessions.DenyAccessToken(r.Context(), principal.TokenId, principal.ExpiresAt); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	if body.RefreshToken != "" {
+		err := handler.sessions.RevokeRefreshToken(r.Context(), principal.UserId, authentication.HashRefreshToken(body.RefreshToken))
+		if err != nil {
+			responses.ServerError(w, r, err)
+			return
+		}
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+var errAccountSuspended = errors.New("the account is suspended")
+
+func (handler *AuthHandler) respondWithTokens(w http.ResponseWriter, r *http.Request, user models.User, refreshToken string) {
+	token, err := handler.tokens.Issue(user.Id, user.Role)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, responses.AuthResponse{
+		Id:           user.Id.String(),
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int(config.AccessTokenTTL.Seconds()),
+	})
+}
@@ -0,0 +1,97 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRefresh(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	users := repositories.NewMockUserRepository(ctrl)
+	sessions := repositories.NewMockSessionRepository(ctrl)
+	handler := controllers.NewAuthHandler(users, sessions, testTokens(t))
+
+	userId := uuid.New()
+	var issuedHash string
+	sessions.EXPECT().
+		RotateRefreshToken(gomock.Any(), authentication.HashRefreshToken("old-token"), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_, _ interface{}, newTokenHash string, _ interface{}) (uuid.UUID, error) {
+			issuedHash = newTokenHash
+			return userId, nil
+		})
+	users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, Role: models.RoleModerator}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refreshToken": "old-token"}`))
+	handler.Refresh(w, r)
+
+	var response responses.AuthResponse
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, userId.String(), response.Id)
+	assert.NotEmpty(t, response.Token)
+	assert.Equal(t, issuedHash, authentication.HashRefreshToken(response.RefreshToken))
+}
+
+func TestRefreshWithReusedToken(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sessions := repositories.NewMockSessionRepository(ctrl)
+	handler := controllers.NewAuthHandler(repositories.NewMockUserRepository(ctrl), sessions, testTokens(t))
+
+	sessions.EXPECT().
+		RotateRefreshToken(gomock.Any(), authentication.HashRefreshToken("used-token"), gomock.Any(), gomock.Any()).
+		Return(uuid.Nil, repositories.ErrRefreshTokenReused)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refreshToken": "used-token"}`))
+	handler.Refresh(w, r)
+
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestLogout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	sessions := repositories.NewMockSessionRepository(ctrl)
+	handler := controllers.NewAuthHandler(repositories.NewMockUserRepository(ctrl), sessions, testTokens(t))
+
+	userId := uuid.New()
+	sessions.EXPECT().DenyAccessToken(gomock.Any(), gomock.Any(), gomock.Any()).Return(nil)
+	sessions.EXPECT().RevokeRefreshToken(gomock.Any(), userId, authentication.HashRefreshToken("token")).Return(nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPost, "/api/auth/logout", `{"refreshToken": "token"}`, userId)
+	handler.Logout(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestRefreshOfSuspendedUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	users := repositories.NewMockUserRepository(ctrl)
+	sessions := repositories.NewMockSessionRepository(ctrl)
+	handler := controllers.NewAuthHandler(users, sessions, testTokens(t))
+
+	userId := uuid.New()
+	suspendedAt := time.Now()
+	sessions.EXPECT().RotateRefreshToken(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(userId, nil)
+	users.EXPECT().GetById(gomock.Any(), userId).Return(models.User{Id: userId, SuspendedAt: &suspendedAt}, nil)
+
+	w := httptest.NewRecorder()
+	r := httptest.NewRequest(http.MethodPost, "/api/auth/refresh", strings.NewReader(`{"refreshToken": "token"}`))
+	handler.Refresh(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
@@ -0,0 +1,123 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+// authorize reports whether the user of r may perform action on resource, and
+// answers the request with the reason when not.
+func authorize(w http.ResponseWriter, r *http.Request, action authorization.Action, resource authorization.Resource) bool {
+	err := authorization.Authorize(r.Context(), action, resource)
+	if err == nil {
+		return true
+	}
+
+	if errors.Is(err, authentication.ErrUnauthenticated) {
+		responses.Error(w, http.StatusUnauthorized, err)
+	} else {
+		responses.Error(w, http.StatusForbidden, err)
+	}
+	return false
+}
+
+// moderatedUser returns the user of the id path variable when the user of r
+// may perform action on them, and answers the request otherwise.
+func moderatedUser(w http.ResponseWriter, r *http.Request, users repositories.UserRepository, action authorization.Action) (models.User, bool) {
+	userId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return models.User{}, false
+	}
+
+	user, err := users.GetById(r.Context(), userId)
+	if errors.Is(err, repositories.ErrUserNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return models.User{}, false
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return models.User{}, false
+	}
+
+	if !authorize(w, r, action, authorization.Resource{OwnerId: user.Id, OwnerRole: user.Role}) {
+		return models.User{}, false
+	}
+	return user, true
+}
+
+// audited runs run, which performs action on target on behalf of the user of r,
+// in the transaction its audit entry is recorded in, so the action never takes
+// effect unrecorded. It answers the request when either fails.
+func audited(
+	w http.ResponseWriter,
+	r *http.Request,
+	audit repositories.AuditRepository,
+	action authorization.Action,
+	targetType models.AuditTarget,
+	targetId uuid.UUID,
+	details interface{},
+	run func(ctx context.Context) error,
+) bool {
+	principal, _ := authentication.PrincipalFrom(r.Context())
+	entry := models.AuditEntry{
+		ActorId:    principal.UserId,
+		Action:     string(action),
+		TargetType: targetType,
+		TargetId:   targetId,
+	}
+
+	if details != nil {
+		var err error
+		if entry.Details, err = json.Marshal(details); err != nil {
+			responses.ServerError(w, r, err)
+			return false
+		}
+	}
+
+	return succeeded(w, r, audit.Record(r.Context(), entry, run))
+}
+
+// moderated runs run like audited when the user of r acts on a target owned by
+// ownerId, as moderators do, and right away when they own it.
+func moderated(
+	w http.ResponseWriter,
+	r *http.Request,
+	audit repositories.AuditRepository,
+	ownerId uuid.UUID,
+	action authorization.Action,
+	targetType models.AuditTarget,
+	targetId uuid.UUID,
+	details interface{},
+	run func(ctx context.Context) error,
+) bool {
+	principal, _ := authentication.PrincipalFrom(r.Context())
+	if principal.UserId != ownerId {
+		return audited(w, r, audit, action, targetType, targetId, details, run)
+	}
+	return succeeded(w, r, run(r.Context()))
+}
+
+// succeeded reports whether the action that returned err succeeded, and
+// answers the request with err when not.
+func succeeded(w http.ResponseWriter, r *http.Request, err error) bool {
+	if errors.Is(err, repositories.ErrUserNotFound) || errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return false
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return false
+	}
+	return true
+}
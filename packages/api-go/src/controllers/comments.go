@@ -0,0 +1,328 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/metrics"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+type CommentHandler struct {
+	comments      repositories.CommentRepository
+	posts         repositories.PostRepository
+	notifications repositories.NotificationRepository
+	audit         repositories.AuditRepository
+}
+
+func NewCommentHandler(
+	comments repositories.CommentRepository,
+	posts repositories.PostRepository,
+	notifications repositories.NotificationRepository,
+	audit repositories.AuditRepository,
+) *CommentHandler {
+	return &CommentHandler{comments, posts, notifications, audit}
+}
+
+// @Summary      Comment on a post
+// @Description  Creates a comment on a post, or a reply to another comment of the same post when parentId is given.
+// @Tags         Comments
+// @Accept       json
+// @Produce      json
+// @Param        id       path      string          true  "Post ID"
+// @Param        comment  body      models.Comment  true  "Comment content and optional parentId"
+// @Success      201  {object}  models.Comment
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/comments [post]
+func (handler *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	bodyRequest, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var comment models.Comment
+	if err = json.Unmarshal(bodyRequest, &comment); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = comment.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	comment.PostId = postId
+	comment.AuthorId = userId
+
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	parentAuthorId := uuid.Nil
+	if comment.ParentId != nil {
+		parent, err := handler.comments.GetById(r.Context(), *comment.ParentId)
+		if err != nil {
+			responses.ServerError(w, r, err)
+			return
+		}
+
+		if parent.PostId != postId {
+			responses.Error(w, http.StatusBadRequest, errors.New("it is only possible to reply to a comment of the same post"))
+			return
+		}
+		parentAuthorId = parent.AuthorId
+	}
+
+	commentId, err := handler.comments.Create(r.Context(), comment)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+	metrics.CommentsCreated.Inc()
+
+	created, err := handler.comments.GetById(r.Context(), commentId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	notifications := []models.Notification{{
+		UserId:    post.AuthorId,
+		Type:      models.NotificationComment,
+		ActorId:   userId,
+		PostId:    &postId,
+		CommentId: &commentId,
+	}}
+	if parentAuthorId != uuid.Nil && parentAuthorId != post.AuthorId {
+		notifications = append(notifications, models.Notification{
+			UserId:    parentAuthorId,
+			Type:      models.NotificationComment,
+			ActorId:   userId,
+			PostId:    &postId,
+			CommentId: &commentId,
+		})
+	}
+	notify(r.Context(), handler.notifications, notifications...)
+
+	responses.JSON(w, http.StatusCreated, created)
+}
+
+// @Summary      Get the comments of a post
+// @Description  Retrieves a page of top-level comments of a post, oldest first, or of the replies to a comment when parentId is given.
+// @Tags         Comments
+// @Accept       json
+// @Produce      json
+// @Param        id        path      string  true   "Post ID"
+// @Param        parentId  query     string  false  "List the replies to this comment"
+// @Param        limit     query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor    query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.CommentPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/comments [get]
+func (handler *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	parentId := uuid.Nil
+	if rawParentId := r.URL.Query().Get("parentId"); rawParentId != "" {
+		parentId, err = uuid.Parse(rawParentId)
+		if err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	cursor, limit, err := parsePagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := handler.comments.GetByPost(r.Context(), postId, parentId, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, page)
+}
+
+// @Summary      Edit a comment
+// @Description  Updates the content of a comment written by the authenticated user.
+// @Tags         Comments
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string          true  "Post ID"
+// @Param        commentId  path      string          true  "Comment ID"
+// @Param        comment    body      models.Comment  true  "Updated comment content"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/comments/{commentId} [put]
+func (handler *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	commentId, err := uuid.Parse(mux.Vars(r)["commentId"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	commentSaved, err := handler.comments.GetById(r.Context(), commentId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	if commentSaved.PostId != postId {
+		responses.Error(w, http.StatusNotFound, errors.New("comment not found in this post"))
+		return
+	}
+
+	if !authorize(w, r, authorization.UpdateComment, authorization.Resource{OwnerId: commentSaved.AuthorId}) {
+		return
+	}
+
+	bodyRequest, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var comment models.Comment
+	if err = json.Unmarshal(bodyRequest, &comment); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = comment.Prepare(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = handler.comments.Update(r.Context(), commentId, comment.Content); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Delete a comment
+// @Description  Deletes a comment and its replies. Allowed for the comment author, the author of the post and moderators. Deleting the comment of someone else is recorded to the audit trail.
+// @Tags         Comments
+// @Accept       json
+// @Produce      json
+// @Param        id         path      string  true  "Post ID"
+// @Param        commentId  path      string  true  "Comment ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/comments/{commentId} [delete]
+func (handler *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	commentId, err := uuid.Parse(mux.Vars(r)["commentId"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	commentSaved, err := handler.comments.GetById(r.Context(), commentId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	if commentSaved.PostId != postId {
+		responses.Error(w, http.StatusNotFound, errors.New("comment not found in this post"))
+		return
+	}
+
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	resource := authorization.Resource{OwnerId: commentSaved.AuthorId, ParentOwnerId: post.AuthorId}
+	if !authorize(w, r, authorization.DeleteComment, resource) {
+		return
+	}
+
+	// The comment is gone once deleted, so what it said is kept with the entry.
+	details := map[string]interface{}{
+		"authorId": commentSaved.AuthorId,
+		"postId":   postId,
+		"content":  commentSaved.Content,
+	}
+	remove := func(ctx context.Context) error {
+		return handler.comments.Delete(ctx, commentId)
+	}
+	if !moderated(w, r, handler.audit, commentSaved.AuthorId, authorization.DeleteComment, models.AuditTargetComment, commentId, details, remove) {
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
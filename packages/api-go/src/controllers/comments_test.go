@@ -0,0 +1,107 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateCommentOfAnotherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	comments := repositories.NewMockCommentRepository(ctrl)
+	handler := controllers.NewCommentHandler(comments, repositories.NewMockPostRepository(ctrl), repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	commentId := uuid.New()
+	comments.EXPECT().GetById(gomock.Any(), commentId).Return(models.Comment{Id: commentId, PostId: postId, AuthorId: uuid.New()}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodPut, "/api/posts/"+postId.String()+"/comments/"+commentId.String(), `{"content": "mine now"}`, uuid.New(), models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String(), "commentId": commentId.String()})
+	handler.UpdateComment(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestDeleteComment(t *testing.T) {
+	postAuthorId := uuid.New()
+	commentAuthorId := uuid.New()
+
+	tests := []struct {
+		name    string
+		userId  uuid.UUID
+		role    models.Role
+		status  int
+		audited bool
+	}{
+		{"comment author", commentAuthorId, models.RoleUser, http.StatusNoContent, false},
+		{"post author", postAuthorId, models.RoleUser, http.StatusNoContent, true},
+		{"moderator", uuid.New(), models.RoleModerator, http.StatusNoContent, true},
+		{"another user", uuid.New(), models.RoleUser, http.StatusForbidden, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			comments := repositories.NewMockCommentRepository(ctrl)
+			posts := repositories.NewMockPostRepository(ctrl)
+			audit := repositories.NewMockAuditRepository(ctrl)
+			handler := controllers.NewCommentHandler(comments, posts, repositories.NewMockNotificationRepository(ctrl), audit)
+
+			postId := uuid.New()
+			commentId := uuid.New()
+			comments.EXPECT().GetById(gomock.Any(), commentId).Return(models.Comment{Id: commentId, PostId: postId, AuthorId: commentAuthorId, Content: "spam"}, nil)
+			posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: postAuthorId}, nil)
+			if test.status == http.StatusNoContent {
+				comments.EXPECT().Delete(gomock.Any(), commentId).Return(nil)
+			}
+			if test.audited {
+				audit.EXPECT().
+					Record(gomock.Any(), gomock.Any(), gomock.Any()).
+					DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+						assert.Equal(t, models.AuditEntry{
+							ActorId:    test.userId,
+							Action:     string(authorization.DeleteComment),
+							TargetType: models.AuditTargetComment,
+							TargetId:   commentId,
+							Details:    entry.Details,
+						}, entry)
+						assert.JSONEq(t, `{"authorId": "`+commentAuthorId.String()+`", "postId": "`+postId.String()+`", "content": "spam"}`, string(entry.Details))
+					}))
+			}
+
+			w := httptest.NewRecorder()
+			r := authenticatedRequestAs(t, http.MethodDelete, "/api/posts/"+postId.String()+"/comments/"+commentId.String(), "", test.userId, test.role)
+			r = mux.SetURLVars(r, map[string]string{"id": postId.String(), "commentId": commentId.String()})
+			handler.DeleteComment(w, r)
+
+			assert.Equal(t, test.status, w.Code)
+		})
+	}
+}
+
+func TestDeleteCommentOfAnotherPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	comments := repositories.NewMockCommentRepository(ctrl)
+	handler := controllers.NewCommentHandler(comments, repositories.NewMockPostRepository(ctrl), repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	commentId := uuid.New()
+	comments.EXPECT().GetById(gomock.Any(), commentId).Return(models.Comment{Id: commentId, PostId: uuid.New()}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodDelete, "/api/posts/"+postId.String()+"/comments/"+commentId.String(), "", uuid.New(), models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String(), "commentId": commentId.String()})
+	handler.DeleteComment(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,56 @@
+package controllers
+
+import (
+	"database/sql"
+
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/redis/go-redis/v9"
+)
+
+// Handlers groups the handlers the routes are bound to.
+type Handlers struct {
+	Users         *UserHandler
+	Posts         *PostHandler
+	Comments      *CommentHandler
+	Search        *SearchHandler
+	Tags          *TagHandler
+	Notifications *NotificationHandler
+	Health        *HealthHandler
+	Auth          *AuthHandler
+	Keys          *KeyHandler
+	Admin         *AdminHandler
+	// Tokens and Sessions are checked by the authentication middleware, for
+	// valid and for revoked tokens.
+	Tokens   authentication.TokenVerifier
+	Sessions repositories.SessionRepository
+}
+
+// NewHandlers builds every handler on repositories sharing db and redis,
+// issuing access tokens with tokens and publishing the public keys of keys.
+func NewHandlers(db *sql.DB, redis *redis.Client, keys *authentication.KeySet, tokens *authentication.Tokens) Handlers {
+	users := repositories.NewUserRepository(db)
+	posts := repositories.NewPostRepository(db, redis)
+	comments := repositories.NewCommentRepository(db, redis)
+	tags := repositories.NewTagRepository(redis)
+	notifications := repositories.NewNotificationRepository(db, redis)
+	sessions := repositories.NewSessionRepository(db, redis, config.AccessTokenTTL, config.TokenLeeway)
+	audit := repositories.NewAuditRepository(db)
+	stats := repositories.NewStatsRepository(db)
+
+	return Handlers{
+		Users:         NewUserHandler(users, notifications, sessions),
+		Posts:         NewPostHandler(posts, notifications, audit),
+		Comments:      NewCommentHandler(comments, posts, notifications, audit),
+		Search:        NewSearchHandler(posts, users),
+		Tags:          NewTagHandler(posts, tags),
+		Notifications: NewNotificationHandler(notifications),
+		Health:        NewHealthHandler(db, redis),
+		Auth:          NewAuthHandler(users, sessions, tokens),
+		Keys:          NewKeyHandler(keys),
+		Admin:         NewAdminHandler(users, posts, sessions, audit, stats),
+		Tokens:        tokens,
+		Sessions:      sessions,
+	}
+}
@@ -0,0 +1,87 @@
+package controllers
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/responses"
+	"github.com/redis/go-redis/v9"
+)
+
+// pingTimeout bounds each dependency check of /readyz, so a hung dependency
+// fails the probe instead of stalling it.
+const pingTimeout = 2 * time.Second
+
+type HealthHandler struct {
+	pings map[string]func(context.Context) error
+}
+
+func NewHealthHandler(db *sql.DB, redis *redis.Client) *HealthHandler {
+	return &HealthHandler{pings: map[string]func(context.Context) error{
+		"postgres": db.PingContext,
+		"redis": func(ctx context.Context) error {
+			return redis.Ping(ctx).Err()
+		},
+	}}
+}
+
+// Live answers as long as the process serves requests. It checks no
+// dependency, so an outage of Postgres or Redis does not get it restarted.
+func (handler *HealthHandler) Live(w http.ResponseWriter, r *http.Request) {
+	responses.JSON(w, http.StatusOK, models.HealthReport{Status: models.StatusUp})
+}
+
+// Ready pings every dependency concurrently and answers 503 when one of them
+// is down, so no traffic is routed to an instance that cannot serve it.
+func (handler *HealthHandler) Ready(w http.ResponseWriter, r *http.Request) {
+	report := models.HealthReport{
+		Status:       models.StatusUp,
+		Dependencies: make(map[string]models.DependencyHealth, len(handler.pings)),
+	}
+
+	var mutex sync.Mutex
+	var wg sync.WaitGroup
+	for name, ping := range handler.pings {
+		wg.Add(1)
+		go func(name string, ping func(context.Context) error) {
+			defer wg.Done()
+			health := check(r.Context(), ping)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Dependencies[name] = health
+			if health.Status == models.StatusDown {
+				report.Status = models.StatusDown
+			}
+		}(name, ping)
+	}
+	wg.Wait()
+
+	status := http.StatusOK
+	if report.Status == models.StatusDown {
+		status = http.StatusServiceUnavailable
+	}
+	responses.JSON(w, status, report)
+}
+
+func check(ctx context.Context, ping func(context.Context) error) models.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	start := time.Now()
+	err := ping(ctx)
+	health := models.DependencyHealth{
+		Status:    models.StatusUp,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		health.Status = models.StatusDown
+		health.Error = err.Error()
+	}
+
+	return health
+}
@@ -0,0 +1,53 @@
+package controllers_test
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReady(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	redis, redisMock := redismock.NewClientMock()
+
+	mock.ExpectPing()
+	redisMock.ExpectPing().SetVal("PONG")
+
+	w := httptest.NewRecorder()
+	controllers.NewHealthHandler(db, redis).Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report models.HealthReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, models.StatusUp, report.Status)
+	assert.Equal(t, models.StatusUp, report.Dependencies["postgres"].Status)
+	assert.Equal(t, models.StatusUp, report.Dependencies["redis"].Status)
+}
+
+func TestReadyWithRedisDown(t *testing.T) {
+	db, mock, err := sqlmock.New(sqlmock.MonitorPingsOption(true))
+	assert.NoError(t, err)
+	redis, redisMock := redismock.NewClientMock()
+
+	mock.ExpectPing()
+	redisMock.ExpectPing().SetErr(errors.New("connection refused"))
+
+	w := httptest.NewRecorder()
+	controllers.NewHealthHandler(db, redis).Ready(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+	var report models.HealthReport
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &report))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, models.StatusDown, report.Status)
+	assert.Equal(t, models.StatusUp, report.Dependencies["postgres"].Status)
+	assert.Equal(t, "connection refused", report.Dependencies["redis"].Error)
+}
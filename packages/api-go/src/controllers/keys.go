@@ -0,0 +1,25 @@
+package controllers
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+type KeyHandler struct {
+	keys *authentication.KeySet
+}
+
+func NewKeyHandler(keys *authentication.KeySet) *KeyHandler {
+	return &KeyHandler{keys}
+}
+
+// JWKS publishes the public keys access tokens are verified with, so other
+// services can verify them without the private key.
+func (handler *KeyHandler) JWKS(w http.ResponseWriter, r *http.Request) {
+	// Caching stays well below the access token lifetime, so a new signing
+	// key is picked up before many tokens it signs are around.
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	responses.JSON(w, http.StatusOK, handler.keys.JWKS())
+}
@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/logging"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+type NotificationHandler struct {
+	notifications repositories.NotificationRepository
+}
+
+func NewNotificationHandler(notifications repositories.NotificationRepository) *NotificationHandler {
+	return &NotificationHandler{notifications}
+}
+
+// notify records notifications produced by an action that already succeeded.
+// A failure is logged instead of failing the request, so the client does not
+// retry an action that was applied.
+func notify(ctx context.Context, repository repositories.NotificationRepository, notifications ...models.Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+	if err := repository.Create(ctx, notifications...); err != nil {
+		logging.FromContext(ctx).Error("recording notifications failed",
+			slog.Int("count", len(notifications)), slog.String("error", err.Error()))
+	}
+}
+
+type readNotificationsRequest struct {
+	Ids []uuid.UUID `json:"ids"`
+}
+
+// @Summary      Get notifications
+// @Description  Retrieves a page of the notifications of the authenticated user, newest first, along with the number of unread ones.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        unread  query     bool    false  "Only list unread notifications"
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.NotificationPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /notifications [get]
+func (handler *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	cursor, limit, err := parsePagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	unreadOnly := r.URL.Query().Get("unread") == "true"
+
+	page, err := handler.notifications.GetByUser(r.Context(), userId, unreadOnly, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, page)
+}
+
+// @Summary      Mark notifications as read
+// @Description  Marks the given notifications of the authenticated user as read, or all of them when no ids are given.
+// @Tags         Notifications
+// @Accept       json
+// @Produce      json
+// @Param        ids  body  readNotificationsRequest  false  "Ids of the notifications to mark as read"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /notifications/read [post]
+func (handler *NotificationHandler) ReadNotifications(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	bodyRequest, err := io.ReadAll(r.Body)
+	if err != nil {
+		responses.Error(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var request readNotificationsRequest
+	if len(bodyRequest) > 0 {
+		if err = json.Unmarshal(bodyRequest, &request); err != nil {
+			responses.Error(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	if err = handler.notifications.MarkRead(r.Context(), userId, request.Ids); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
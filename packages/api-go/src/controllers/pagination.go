@@ -0,0 +1,63 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// parsePagination reads the ?limit= and ?cursor= query parameters shared by the
+// paginated endpoints, applying the default page size when limit is omitted.
+func parsePagination(r *http.Request) (models.Cursor, int, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return models.Cursor{}, 0, err
+	}
+
+	cursor, err := models.DecodeCursor(r.URL.Query().Get("cursor"))
+	if err != nil {
+		return models.Cursor{}, 0, err
+	}
+
+	return cursor, limit, nil
+}
+
+// parseOffsetPagination reads the ?limit= and ?offset= query parameters of the
+// endpoints whose results are ranked rather than ordered by creation time.
+func parseOffsetPagination(r *http.Request) (int, int, error) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	offset := 0
+	if rawOffset := r.URL.Query().Get("offset"); rawOffset != "" {
+		offset, err = strconv.Atoi(rawOffset)
+		if err != nil || offset < 0 {
+			return 0, 0, errors.New("the offset must be a positive number")
+		}
+	}
+
+	return offset, limit, nil
+}
+
+func parseLimit(r *http.Request) (int, error) {
+	rawLimit := r.URL.Query().Get("limit")
+	if rawLimit == "" {
+		return defaultPageSize, nil
+	}
+
+	limit, err := strconv.Atoi(rawLimit)
+	if err != nil || limit < 1 || limit > maxPageSize {
+		return 0, errors.New("the limit must be a number between 1 and 100")
+	}
+
+	return limit, nil
+}
@@ -1,6 +1,7 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -10,12 +11,38 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/otaviopontes/api-go/src/authentication"
-	"github.com/otaviopontes/api-go/src/database"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/metrics"
 	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/realtime"
 	"github.com/otaviopontes/api-go/src/repositories"
 	"github.com/otaviopontes/api-go/src/responses"
 )
 
+type PostHandler struct {
+	posts         repositories.PostRepository
+	notifications repositories.NotificationRepository
+	audit         repositories.AuditRepository
+}
+
+func NewPostHandler(
+	posts repositories.PostRepository,
+	notifications repositories.NotificationRepository,
+	audit repositories.AuditRepository,
+) *PostHandler {
+	return &PostHandler{posts, notifications, audit}
+}
+
+// deletedPost returns the details of the audit entry of deleting post. The post
+// is gone then, so what it said is kept with the entry.
+func deletedPost(post models.Post) map[string]interface{} {
+	return map[string]interface{}{
+		"authorId": post.AuthorId,
+		"title":    post.Title,
+		"content":  post.Content,
+	}
+}
+
 // @Summary      Create a new post
 // @Description  Creates a post associated with the authenticated user.
 // @Tags         Posts
@@ -27,9 +54,11 @@ import (
 // @Failure      400   {object}  responses.ErrorResponse
 // @Failure      401   {object}  responses.ErrorResponse
 // @Failure      500   {object}  responses.ErrorResponse
+// @Failure      503   {object}  responses.ErrorResponse
+// @Failure      504   {object}  responses.ErrorResponse
 // @Router       /posts [post]
-func CreatePost(w http.ResponseWriter, r *http.Request) {
-	userId, err := authentication.ExtractUserId(r)
+func (handler *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
 
 	if err != nil {
 		responses.Error(w, http.StatusUnauthorized, err)
@@ -57,65 +86,54 @@ func CreatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := database.Connect()
+	post, err = handler.posts.Create(r.Context(), userId, post)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	defer db.Close()
+	notify(r.Context(), handler.notifications, models.MentionNotifications(post)...)
+	metrics.PostsCreated.Inc()
+	realtime.Publish(realtime.PostCreated, post)
 
-	redis, err := database.ConnectRedis()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	repository := repositories.NewPostRepository(db, redis)
-
-	err = repository.Create(userId, post)
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	responses.JSON(w, http.StatusCreated, nil)
+	responses.JSON(w, http.StatusCreated, post)
 
 }
 
-// @Summary      Get all posts
-// @Description  Retrieves a list of all posts from the database.
+// @Summary      Get posts
+// @Description  Retrieves a page of posts, newest first, with the reaction counts and the reaction of the authenticated user. Pass the returned nextCursor as cursor to fetch the following page.
 // @Tags         Posts
 // @Accept       json
 // @Produce      json
-// @Success      200  {array}  models.Post
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object} models.PostPage
+// @Failure      400  {object} responses.ErrorResponse
+// @Failure      401  {object} responses.ErrorResponse
 // @Failure      500  {object} responses.ErrorResponse
+// @Failure      503  {object} responses.ErrorResponse
+// @Failure      504  {object} responses.ErrorResponse
 // @Router       /posts [get]
-func GetPosts(w http.ResponseWriter, r *http.Request) {
-
-	db, err := database.Connect()
+func (handler *PostHandler) GetPosts(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	defer db.Close()
-
-	redis, err := database.ConnectRedis()
+	cursor, limit, err := parsePagination(r)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	repository := repositories.NewPostRepository(db, redis)
-
-	posts, err := repository.GetPosts()
+	page, err := handler.posts.GetPosts(r.Context(), userId, cursor, limit)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	responses.JSON(w, http.StatusOK, posts)
+	responses.JSON(w, http.StatusOK, page)
 
 }
 
@@ -127,34 +145,25 @@ func GetPosts(w http.ResponseWriter, r *http.Request) {
 // @Param        id   path      string  true  "Post ID"
 // @Success      200  {object}  models.Post
 // @Failure      400  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /posts/{id} [get]
-func GetPost(w http.ResponseWriter, r *http.Request) {
+func (handler *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
 	postId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	defer db.Close()
-
-	redis, err := database.ConnectRedis()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
 		return
 	}
-
-	repository := repositories.NewPostRepository(db, redis)
-
-	post, err := repository.GetPostById(postId)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
@@ -172,46 +181,29 @@ func GetPost(w http.ResponseWriter, r *http.Request) {
 // @Failure      400  {object}  responses.ErrorResponse
 // @Failure      401  {object}  responses.ErrorResponse
 // @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /posts/{id} [put]
-func UpdatePost(w http.ResponseWriter, r *http.Request) {
-	userId, err := authentication.ExtractUserId(r)
-
-	if err != nil {
-		responses.Error(w, http.StatusUnauthorized, err)
-		return
-	}
-
+func (handler *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
 	postId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+	postSaved, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
 		return
 	}
-
-	defer db.Close()
-
-	redis, err := database.ConnectRedis()
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	repository := repositories.NewPostRepository(db, redis)
-
-	postSaved, err := repository.GetPostById(postId)
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-
-	if postSaved.AuthorId != userId {
-		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to update a post that is not yours"))
+	if !authorize(w, r, authorization.UpdatePost, authorization.Resource{OwnerId: postSaved.AuthorId}) {
 		return
 	}
 	bodyRequest, err := io.ReadAll(r.Body)
@@ -235,18 +227,25 @@ func UpdatePost(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	err = repository.Update(postId, post)
+	post, err = handler.posts.Update(r.Context(), postId, post)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
+	notify(r.Context(), handler.notifications, models.MentionNotifications(post)...)
+	realtime.Publish(realtime.PostUpdated, post)
+
 	responses.JSON(w, http.StatusNoContent, nil)
 
 }
 
 // @Summary      Delete a post
-// @Description  Deletes a post that belongs to the authenticated user.
+// @Description  Deletes a post that belongs to the authenticated user. Moderators and admins can delete any post, which is recorded to the audit trail.
 // @Tags         Posts
 // @Accept       json
 // @Produce      json
@@ -255,145 +254,280 @@ func UpdatePost(w http.ResponseWriter, r *http.Request) {
 // @Failure      400  {object}  responses.ErrorResponse
 // @Failure      401  {object}  responses.ErrorResponse
 // @Failure      403  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /posts/{id} [delete]
-func DeletePost(w http.ResponseWriter, r *http.Request) {
-	userId, err := authentication.ExtractUserId(r)
-
-	if err != nil {
-		responses.Error(w, http.StatusUnauthorized, err)
-		return
-	}
-
+func (handler *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
 	postId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
+	postSaved, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	defer db.Close()
+	if !authorize(w, r, authorization.DeletePost, authorization.Resource{OwnerId: postSaved.AuthorId}) {
+		return
+	}
 
-	redis, err := database.ConnectRedis()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+	remove := func(ctx context.Context) error {
+		return handler.posts.Delete(ctx, postId)
+	}
+	if !moderated(w, r, handler.audit, postSaved.AuthorId, authorization.DeletePost, models.AuditTargetPost, postId, deletedPost(postSaved), remove) {
 		return
 	}
 
-	repository := repositories.NewPostRepository(db, redis)
+	realtime.Publish(realtime.PostDeleted, realtime.PostRef{Id: postId})
 
-	postSaved, err := repository.GetPostById(postId)
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Like a post
+// @Description  Sets the reaction of the authenticated user to a post to like. Shortcut for PUT /posts/{id}/reactions/like.
+// @Tags         Posts
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "Post ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/like [post]
+func (handler *PostHandler) LikePost(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	if postSaved.AuthorId != userId {
-		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to delete a post that is not yours"))
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
-		responses.Error(w, http.StatusUnprocessableEntity, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	err = repository.Delete(postId)
+	err = handler.posts.React(r.Context(), userId, postId, models.ReactionLike)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
+	metrics.Reactions.WithLabelValues(string(models.ReactionLike)).Inc()
+	realtime.Publish(realtime.PostLiked, realtime.ReactionChange{PostId: postId, UserId: userId, Reaction: models.ReactionLike})
+
+	notify(r.Context(), handler.notifications, models.Notification{
+		UserId:   post.AuthorId,
+		Type:     models.NotificationReaction,
+		ActorId:  userId,
+		PostId:   &postId,
+		Reaction: models.ReactionLike,
+	})
+
 	responses.JSON(w, http.StatusNoContent, nil)
+
 }
 
-// @Summary      Like a post
-// @Description  Allows a user to like a post by its ID.
+// @Summary      Unlike a post
+// @Description  Removes the like given by the authenticated user to a post, if any. Shortcut for DELETE /posts/{id}/reactions/like.
 // @Tags         Posts
 // @Accept       json
 // @Produce      json
 // @Param        id   path      string  true  "Post ID"
 // @Success      204
 // @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
-// @Router       /posts/{id}/like [post]
-func LikePost(w http.ResponseWriter, r *http.Request) {
-	userId, err := uuid.Parse(mux.Vars(r)["id"])
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/dislike [post]
+func (handler *PostHandler) DislikePost(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
+	err = handler.posts.RemoveReaction(r.Context(), userId, postId, models.ReactionLike)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	defer db.Close()
+	realtime.Publish(realtime.PostLiked, realtime.ReactionChange{PostId: postId, UserId: userId, Reaction: models.ReactionLike, Removed: true})
+
+	responses.JSON(w, http.StatusNoContent, nil)
+
+}
 
-	redis, err := database.ConnectRedis()
+// @Summary      React to a post
+// @Description  Sets the reaction of the authenticated user to a post, replacing any previous one.
+// @Tags         Posts
+// @Accept       json
+// @Produce      json
+// @Param        id    path      string  true  "Post ID"
+// @Param        type  path      string  true  "Reaction type"  Enums(like, love, laugh, sad, angry)
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      404  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/reactions/{type} [put]
+func (handler *PostHandler) ReactToPost(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	repository := repositories.NewPostRepository(db, redis)
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
 
-	err = repository.Like(userId)
+	reaction := models.Reaction(mux.Vars(r)["type"])
+	if err := reaction.Validate(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	post, err := handler.posts.GetPostById(r.Context(), postId)
+	if errors.Is(err, repositories.ErrPostNotFound) {
+		responses.Error(w, http.StatusNotFound, err)
+		return
+	}
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	responses.JSON(w, http.StatusNoContent, nil)
+	err = handler.posts.React(r.Context(), userId, postId, reaction)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
 
+	metrics.Reactions.WithLabelValues(string(reaction)).Inc()
+	realtime.Publish(realtime.PostLiked, realtime.ReactionChange{PostId: postId, UserId: userId, Reaction: reaction})
+
+	notify(r.Context(), handler.notifications, models.Notification{
+		UserId:   post.AuthorId,
+		Type:     models.NotificationReaction,
+		ActorId:  userId,
+		PostId:   &postId,
+		Reaction: reaction,
+	})
+
+	responses.JSON(w, http.StatusNoContent, nil)
 }
 
-// @Summary      Dislike a post
-// @Description  Allows a user to dislike a post by its ID.
+// @Summary      Remove a reaction from a post
+// @Description  Removes the reaction of the authenticated user to a post when it is of the given type.
 // @Tags         Posts
 // @Accept       json
 // @Produce      json
-// @Param        id   path      string  true  "Post ID"
+// @Param        id    path      string  true  "Post ID"
+// @Param        type  path      string  true  "Reaction type"  Enums(like, love, laugh, sad, angry)
 // @Success      204
 // @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
-// @Router       /posts/{id}/dislike [post]
-func DislikePost(w http.ResponseWriter, r *http.Request) {
-	userId, err := uuid.Parse(mux.Vars(r)["id"])
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /posts/{id}/reactions/{type} [delete]
+func (handler *PostHandler) RemovePostReaction(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
 	if err != nil {
-		responses.Error(w, http.StatusBadRequest, err)
+		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	db, err := database.Connect()
+	postId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	defer db.Close()
+	reaction := models.Reaction(mux.Vars(r)["type"])
+	if err := reaction.Validate(); err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
 
-	redis, err := database.ConnectRedis()
+	err = handler.posts.RemoveReaction(r.Context(), userId, postId, reaction)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	repository := repositories.NewPostRepository(db, redis)
+	realtime.Publish(realtime.PostLiked, realtime.ReactionChange{PostId: postId, UserId: userId, Reaction: reaction, Removed: true})
 
-	err = repository.Dislike(userId)
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Get the home timeline
+// @Description  Retrieves a page of posts written by the accounts the authenticated user follows, newest first.
+// @Tags         Posts
+// @Accept       json
+// @Produce      json
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.PostPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /timeline [get]
+func (handler *PostHandler) GetTimeline(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	cursor, limit, err := parsePagination(r)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	responses.JSON(w, http.StatusNoContent, nil)
+	page, err := handler.posts.GetTimeline(r.Context(), userId, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
 
+	responses.JSON(w, http.StatusOK, page)
 }
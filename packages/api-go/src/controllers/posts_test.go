@@ -0,0 +1,271 @@
+package controllers_test
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/controllers"
+	middlewares "github.com/otaviopontes/api-go/src/middleware"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTokens issues and verifies tokens with a key generated for the test.
+func testTokens(t *testing.T) *authentication.Tokens {
+	_, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	keys, err := authentication.NewKeySet(private)
+	assert.NoError(t, err)
+	tokens, err := authentication.NewTokens(keys, authentication.TokenOptions{
+		Issuer:     "test",
+		Audience:   "test",
+		TTL:        time.Minute,
+		Algorithms: []string{"EdDSA"},
+	})
+	assert.NoError(t, err)
+	return tokens
+}
+
+// authenticatedRequest builds a request carrying a token of userId, with its
+// principal in the context as the authentication middleware leaves it.
+func authenticatedRequest(t *testing.T, method, target, body string, userId uuid.UUID) *http.Request {
+	return authenticatedRequestAs(t, method, target, body, userId, models.RoleUser)
+}
+
+// authenticatedRequestAs is authenticatedRequest for a user of role.
+func authenticatedRequestAs(t *testing.T, method, target, body string, userId uuid.UUID, role models.Role) *http.Request {
+	tokens := testTokens(t)
+
+	token, err := tokens.Issue(userId, role)
+	assert.NoError(t, err)
+
+	r := httptest.NewRequest(method, target, strings.NewReader(body))
+	r.Header.Set("Authorization", "Bearer "+token)
+
+	principal, err := tokens.Verify(token)
+	assert.NoError(t, err)
+	return r.WithContext(authentication.WithPrincipal(r.Context(), principal))
+}
+
+func TestCreatePostNotifiesMentionedUsers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	notifications := repositories.NewMockNotificationRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, notifications, repositories.NewMockAuditRepository(ctrl))
+
+	userId := uuid.New()
+	postId := uuid.New()
+	aliceId := uuid.New()
+
+	posts.EXPECT().
+		Create(gomock.Any(), userId, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, post models.Post) (models.Post, error) {
+			assert.Equal(t, []string{"go"}, post.Tags)
+			post.Id = postId
+			post.CreatedAt = time.Now()
+			post.Mentions = []models.Mention{{UserId: aliceId, Nick: "alice", Offset: 3, Length: 6}}
+			return post, nil
+		})
+	notifications.EXPECT().
+		Create(gomock.Any(), models.Notification{UserId: aliceId, Type: models.NotificationMention, ActorId: userId, PostId: &postId}).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPost, "/api/posts", `{"title": "Hi", "content": "hi @alice, #go"}`, userId)
+	handler.CreatePost(w, r)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var created models.Post
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &created))
+	assert.Equal(t, postId, created.Id)
+	assert.Equal(t, userId, created.AuthorId)
+}
+
+func TestCreatePostWithoutTitle(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := controllers.NewPostHandler(repositories.NewMockPostRepository(ctrl), repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPost, "/api/posts", `{"content": "no title"}`, uuid.New())
+	handler.CreatePost(w, r)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestUpdatePostOfAnotherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: uuid.New()}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPut, "/api/posts/"+postId.String(), `{"title": "Mine", "content": "now"}`, uuid.New())
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.UpdatePost(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestModeratorDeletesPostOfAnotherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	audit := repositories.NewMockAuditRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), audit)
+
+	moderatorId := uuid.New()
+	postId := uuid.New()
+	authorId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: authorId, Title: "Spam", Content: "buy now"}, nil)
+	posts.EXPECT().Delete(gomock.Any(), postId).Return(nil)
+	audit.EXPECT().
+		Record(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(recordAndRun(func(entry models.AuditEntry) {
+			assert.Equal(t, moderatorId, entry.ActorId)
+			assert.Equal(t, string(authorization.DeletePost), entry.Action)
+			assert.Equal(t, models.AuditTargetPost, entry.TargetType)
+			assert.Equal(t, postId, entry.TargetId)
+			assert.JSONEq(t, `{"authorId": "`+authorId.String()+`", "title": "Spam", "content": "buy now"}`, string(entry.Details))
+		}))
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodDelete, "/api/posts/"+postId.String(), "", moderatorId, models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.DeletePost(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestModeratorDeletesMissingPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{}, repositories.ErrPostNotFound)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequestAs(t, http.MethodDelete, "/api/posts/"+postId.String(), "", uuid.New(), models.RoleModerator)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.DeletePost(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestUpdatePostDeletedMeanwhile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	userId := uuid.New()
+	postId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: userId}, nil)
+	posts.EXPECT().Update(gomock.Any(), postId, gomock.Any()).Return(models.Post{}, repositories.ErrPostNotFound)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPut, "/api/posts/"+postId.String(), `{"title": "Mine", "content": "now"}`, userId)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.UpdatePost(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDeletePostOfAnotherUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: uuid.New()}, nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodDelete, "/api/posts/"+postId.String(), "", uuid.New())
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	handler.DeletePost(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestReactToPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	notifications := repositories.NewMockNotificationRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, notifications, repositories.NewMockAuditRepository(ctrl))
+
+	userId := uuid.New()
+	authorId := uuid.New()
+	postId := uuid.New()
+
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{Id: postId, AuthorId: authorId}, nil)
+	posts.EXPECT().React(gomock.Any(), userId, postId, models.ReactionLaugh).Return(nil)
+	notifications.EXPECT().
+		Create(gomock.Any(), models.Notification{
+			UserId:   authorId,
+			Type:     models.NotificationReaction,
+			ActorId:  userId,
+			PostId:   &postId,
+			Reaction: models.ReactionLaugh,
+		}).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPut, "/api/posts/"+postId.String()+"/reactions/laugh", "", userId)
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String(), "type": "laugh"})
+	handler.ReactToPost(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestReactToMissingPost(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	postId := uuid.New()
+	posts.EXPECT().GetPostById(gomock.Any(), postId).Return(models.Post{}, repositories.ErrPostNotFound)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPut, "/api/posts/"+postId.String()+"/reactions/like", "", uuid.New())
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String(), "type": "like"})
+	handler.ReactToPost(w, r)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetPostTimedOut(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	posts := repositories.NewMockPostRepository(ctrl)
+	handler := controllers.NewPostHandler(posts, repositories.NewMockNotificationRepository(ctrl), repositories.NewMockAuditRepository(ctrl))
+
+	config.RequestTimeout = 0
+	postId := uuid.New()
+	posts.EXPECT().
+		GetPostById(gomock.Any(), postId).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (models.Post, error) {
+			<-ctx.Done()
+			return models.Post{}, errors.New("pq: canceling statement due to user request")
+		})
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodGet, "/api/posts/"+postId.String(), "", uuid.New())
+	r = mux.SetURLVars(r, map[string]string{"id": postId.String()})
+	middlewares.Timeout(handler.GetPost)(w, r)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+}
@@ -0,0 +1,71 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/realtime"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 1024,
+	Subprotocols:    []string{authentication.WebSocketProtocol},
+	CheckOrigin: func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		return origin == "" || origin == config.FrontEndUrl
+	},
+}
+
+// @Summary      Subscribe to real-time events
+// @Description  Upgrades to a WebSocket that pushes post.created, post.updated, post.deleted and post.liked events as {"id", "type", "data"} JSON messages. Browsers pass the token as ?access_token= or as the subprotocol following "bearer".
+// @Tags         Realtime
+// @Param        access_token  query  string  false  "JWT, when it cannot be sent in the Authorization header"
+// @Success      101
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Router       /ws [get]
+func SubscribeEvents(w http.ResponseWriter, r *http.Request) {
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// Upgrade has already answered the request with the error.
+		return
+	}
+
+	realtime.Serve(conn)
+}
+
+// @Summary      Stream post events
+// @Description  Streams the same events as /ws as text/event-stream, for clients that cannot use WebSockets. Each event carries its id, type and JSON data. Reconnecting clients send the last id they saw as the Last-Event-ID header, or as ?lastEventId=, to receive the events they missed while they are still kept.
+// @Tags         Realtime
+// @Produce      text/event-stream
+// @Param        Last-Event-ID  header  string  false  "Id of the last event received"
+// @Param        lastEventId    query   string  false  "Id of the last event received, for clients that cannot set headers"
+// @Param        access_token   query   string  false  "JWT, when it cannot be sent in the Authorization header"
+// @Success      200
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Router       /posts/stream [get]
+func StreamEvents(w http.ResponseWriter, r *http.Request) {
+	lastEventId := r.Header.Get("Last-Event-ID")
+	if lastEventId == "" {
+		lastEventId = r.URL.Query().Get("lastEventId")
+	}
+
+	err := realtime.Stream(w, r, lastEventId)
+	switch {
+	case err == nil:
+	case errors.Is(err, realtime.ErrInvalidEventId):
+		responses.Error(w, http.StatusBadRequest, err)
+	case errors.Is(err, realtime.ErrStopped):
+		responses.Error(w, http.StatusServiceUnavailable, err)
+	default:
+		responses.ServerError(w, r, err)
+	}
+}
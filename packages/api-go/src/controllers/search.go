@@ -0,0 +1,101 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+type SearchHandler struct {
+	posts repositories.PostRepository
+	users repositories.UserRepository
+}
+
+func NewSearchHandler(posts repositories.PostRepository, users repositories.UserRepository) *SearchHandler {
+	return &SearchHandler{posts, users}
+}
+
+// @Summary      Search posts or users
+// @Description  Full-text search over post titles and contents, or over user names and nicks, best matches first. Pass the returned nextOffset as offset to fetch the following page.
+// @Tags         Search
+// @Accept       json
+// @Produce      json
+// @Param        q       query     string  true   "Search terms"
+// @Param        type    query     string  false  "What to search for (default posts)"  Enums(posts, users)
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        offset  query     int     false  "Number of results to skip"
+// @Success      200  {object}  models.SearchResult
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /search [get]
+func (handler *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	query := strings.TrimSpace(r.URL.Query().Get("q"))
+	if query == "" {
+		responses.Error(w, http.StatusBadRequest, errors.New("the search terms are mandatory and cannot be left blank"))
+		return
+	}
+
+	searchType := r.URL.Query().Get("type")
+	if searchType == "" {
+		searchType = "posts"
+	}
+	if searchType != "posts" && searchType != "users" {
+		responses.Error(w, http.StatusBadRequest, errors.New("the type must be posts or users"))
+		return
+	}
+
+	offset, limit, err := parseOffsetPagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	var result models.SearchResult
+	var found int
+
+	if searchType == "users" {
+		users, err := handler.users.Search(r.Context(), query, limit+1, offset)
+		if err != nil {
+			responses.ServerError(w, r, err)
+			return
+		}
+
+		found = len(users)
+		if found > limit {
+			users = users[:limit]
+		}
+		result.Users = users
+	} else {
+		posts, err := handler.posts.Search(r.Context(), userId, query, limit+1, offset)
+		if err != nil {
+			responses.ServerError(w, r, err)
+			return
+		}
+
+		found = len(posts)
+		if found > limit {
+			posts = posts[:limit]
+		}
+		result.Posts = posts
+	}
+
+	if found > limit {
+		result.NextOffset = offset + limit
+	}
+
+	responses.JSON(w, http.StatusOK, result)
+}
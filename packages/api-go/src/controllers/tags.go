@@ -0,0 +1,94 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/responses"
+)
+
+type TagHandler struct {
+	posts repositories.PostRepository
+	tags  repositories.TagRepository
+}
+
+func NewTagHandler(posts repositories.PostRepository, tags repositories.TagRepository) *TagHandler {
+	return &TagHandler{posts, tags}
+}
+
+// @Summary      Get the posts of a hashtag
+// @Description  Retrieves a page of posts tagged with the given hashtag, newest first.
+// @Tags         Tags
+// @Accept       json
+// @Produce      json
+// @Param        tag     path      string  true   "Hashtag, with or without the leading #"
+// @Param        limit   query     int     false  "Page size (1-100, default 20)"
+// @Param        cursor  query     string  false  "Cursor returned by the previous page"
+// @Success      200  {object}  models.PostPage
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /tags/{tag}/posts [get]
+func (handler *TagHandler) GetTagPosts(w http.ResponseWriter, r *http.Request) {
+	userId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	tag := strings.ToLower(strings.TrimPrefix(mux.Vars(r)["tag"], "#"))
+	if tag == "" {
+		responses.Error(w, http.StatusBadRequest, errors.New("the tag is mandatory and cannot be left blank"))
+		return
+	}
+
+	cursor, limit, err := parsePagination(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	page, err := handler.posts.GetByTag(r.Context(), userId, tag, cursor, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, page)
+}
+
+// @Summary      Get trending hashtags
+// @Description  Retrieves the hashtags used by the most posts over the configured sliding window.
+// @Tags         Tags
+// @Accept       json
+// @Produce      json
+// @Param        limit  query     int  false  "Number of tags (1-100, default 20)"
+// @Success      200  {array}   models.TrendingTag
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /tags/trending [get]
+func (handler *TagHandler) GetTrendingTags(w http.ResponseWriter, r *http.Request) {
+	limit, err := parseLimit(r)
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	trending, err := handler.tags.Trending(r.Context(), time.Duration(config.TrendingWindow)*time.Hour, limit)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, trending)
+}
@@ -9,13 +9,28 @@ import (
 	"github.com/google/uuid"
 	"github.com/gorilla/mux"
 	"github.com/otaviopontes/api-go/src/authentication"
-	"github.com/otaviopontes/api-go/src/database"
+	"github.com/otaviopontes/api-go/src/authorization"
 	"github.com/otaviopontes/api-go/src/models"
 	"github.com/otaviopontes/api-go/src/repositories"
 	"github.com/otaviopontes/api-go/src/responses"
 	"github.com/otaviopontes/api-go/src/security"
+	"github.com/otaviopontes/api-go/src/timelines"
 )
 
+type UserHandler struct {
+	users         repositories.UserRepository
+	notifications repositories.NotificationRepository
+	sessions      repositories.SessionRepository
+}
+
+func NewUserHandler(
+	users repositories.UserRepository,
+	notifications repositories.NotificationRepository,
+	sessions repositories.SessionRepository,
+) *UserHandler {
+	return &UserHandler{users, notifications, sessions}
+}
+
 // @Summary      Create a new user
 // @Description  Allows for the creation of a new user in the system.
 // @Tags         Users
@@ -25,8 +40,10 @@ import (
 // @Success      201
 // @Failure      422  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /users [post]
-func CreateUser(w http.ResponseWriter, r *http.Request) {
+func (handler *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
 	requestBody, err := io.ReadAll(r.Body)
 
 	if err != nil {
@@ -46,17 +63,9 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := database.Connect()
+	err = handler.users.Create(r.Context(), user)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-	defer db.Close()
-
-	repository := repositories.NewUserRepository(db)
-	err = repository.Create(user)
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
@@ -73,27 +82,20 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 // @Success      200  {object}  models.User
 // @Failure      400  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /users/{id} [get]
-func GetUser(w http.ResponseWriter, r *http.Request) {
+func (handler *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
 	userId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	db, err := database.Connect()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-	defer db.Close()
-
-	repository := repositories.NewUserRepository(db)
-
-	user, err := repository.GetById(userId)
+	user, err := handler.users.GetById(r.Context(), userId)
 
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
@@ -112,22 +114,17 @@ func GetUser(w http.ResponseWriter, r *http.Request) {
 // @Failure      401  {object}  responses.ErrorResponse
 // @Failure      403  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /users/{id} [put]
-func UpdateUser(w http.ResponseWriter, r *http.Request) {
+func (handler *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	userId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	userIdFromToken, err := authentication.ExtractUserId(r)
-	if err != nil {
-		responses.Error(w, http.StatusUnauthorized, err)
-		return
-	}
-
-	if userId != userIdFromToken {
-		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to update a user if not yours"))
+	if !authorize(w, r, authorization.UpdateUser, authorization.Resource{OwnerId: userId}) {
 		return
 	}
 
@@ -150,19 +147,10 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := database.Connect()
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
-		return
-	}
-	defer db.Close()
-
-	repository := repositories.NewUserRepository(db)
-
-	err = repository.Update(userId, user)
+	err = handler.users.Update(r.Context(), userId, user)
 
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
 
@@ -181,39 +169,30 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 // @Failure      401  {object}  responses.ErrorResponse
 // @Failure      403  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /users/{id} [delete]
-func DeleteUser(w http.ResponseWriter, r *http.Request) {
+func (handler *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	userId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	userIdFromToken, err := authentication.ExtractUserId(r)
-
-	if err != nil {
-		responses.Error(w, http.StatusUnauthorized, err)
+	if !authorize(w, r, authorization.DeleteUser, authorization.Resource{OwnerId: userId}) {
 		return
 	}
 
-	if userId != userIdFromToken {
-		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to delete a user if not yours"))
-		return
-	}
+	err = handler.users.Delete(r.Context(), userId)
 
-	db, err := database.Connect()
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
-	defer db.Close()
-
-	repository := repositories.NewUserRepository(db)
-
-	err = repository.Delete(userId)
 
-	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+	// The refresh tokens went with the user, the access tokens must follow.
+	if err = handler.sessions.RevokeUser(r.Context(), userId); err != nil {
+		responses.ServerError(w, r, err)
 		return
 	}
 
@@ -222,7 +201,7 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 
 // UpdatePassword godoc
 // @Summary      Update user password
-// @Description  Allows a user to update their password in the system.
+// @Description  Allows a user to update their password in the system. Every session of the user is signed out, this one included.
 // @Tags         Users
 // @Accept       json
 // @Produce      json
@@ -234,22 +213,17 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 // @Failure      403  {object}  responses.ErrorResponse
 // @Failure      422  {object}  responses.ErrorResponse
 // @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
 // @Router       /users/{id}/password [put]
-func UpdatePassword(w http.ResponseWriter, r *http.Request) {
+func (handler *UserHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	userId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
 		responses.Error(w, http.StatusBadRequest, err)
 		return
 	}
 
-	userIdFromToken, err := authentication.ExtractUserId(r)
-	if err != nil {
-		responses.Error(w, http.StatusUnauthorized, err)
-		return
-	}
-
-	if userId != userIdFromToken {
-		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to change other user's password"))
+	if !authorize(w, r, authorization.UpdatePassword, authorization.Resource{OwnerId: userId}) {
 		return
 	}
 
@@ -269,38 +243,174 @@ func UpdatePassword(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	db, err := database.Connect()
+	savedPassword, err := handler.users.SearchPassword(r.Context(), userId)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.ServerError(w, r, err)
 		return
 	}
-	defer db.Close()
 
-	repository := repositories.NewUserRepository(db)
+	if err := security.VerifyPassword(password.Current, savedPassword); err != nil {
+		responses.Error(w, http.StatusUnauthorized, errors.New("it is not possible to change other user's password"))
+		return
+	}
 
-	savedPassword, err := repository.SearchPassword(userId)
+	hashedPassword, err := security.Hash(password.New)
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	if err := security.VerifyPassword(password.Current, savedPassword); err != nil {
-		responses.Error(w, http.StatusUnauthorized, errors.New("it is not possible to change other user's password"))
+	err = handler.users.UpdatePassword(r.Context(), userId, hashedPassword)
+
+	if err != nil {
+		responses.ServerError(w, r, err)
 		return
 	}
 
-	hashedPassword, err := security.Hash(password.New)
+	if err = handler.sessions.RevokeUser(r.Context(), userId); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Follow a user
+// @Description  Makes the authenticated user follow another user. Following the same user again has no effect.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      403  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /users/{id}/follow [post]
+func (handler *UserHandler) FollowUser(w http.ResponseWriter, r *http.Request) {
+	followerId, err := authentication.UserIdFrom(r.Context())
 	if err != nil {
 		responses.Error(w, http.StatusUnauthorized, err)
 		return
 	}
 
-	err = repository.UpdatePassword(userId, hashedPassword)
+	userId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
 
+	if followerId == userId {
+		responses.Error(w, http.StatusForbidden, errors.New("it is not possible to follow yourself"))
+		return
+	}
+
+	if err = handler.users.Follow(r.Context(), followerId, userId); err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	timelines.Rebuild(followerId)
+
+	notify(r.Context(), handler.notifications, models.Notification{
+		UserId:  userId,
+		Type:    models.NotificationFollow,
+		ActorId: followerId,
+	})
+
+	responses.JSON(w, http.StatusNoContent, nil)
+}
+
+// @Summary      Unfollow a user
+// @Description  Makes the authenticated user stop following another user.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      204
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      401  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /users/{id}/follow [delete]
+func (handler *UserHandler) UnfollowUser(w http.ResponseWriter, r *http.Request) {
+	followerId, err := authentication.UserIdFrom(r.Context())
+	if err != nil {
+		responses.Error(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	userId, err := uuid.Parse(mux.Vars(r)["id"])
 	if err != nil {
-		responses.Error(w, http.StatusInternalServerError, err)
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = handler.users.Unfollow(r.Context(), followerId, userId); err != nil {
+		responses.ServerError(w, r, err)
 		return
 	}
 
+	timelines.Rebuild(followerId)
+
 	responses.JSON(w, http.StatusNoContent, nil)
 }
+
+// @Summary      Get followers
+// @Description  Retrieves the users that follow the given user.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {array}   models.User
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /users/{id}/followers [get]
+func (handler *UserHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	followers, err := handler.users.GetFollowers(r.Context(), userId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, followers)
+}
+
+// @Summary      Get followed users
+// @Description  Retrieves the users followed by the given user.
+// @Tags         Users
+// @Accept       json
+// @Produce      json
+// @Param        id   path      string  true  "User ID"
+// @Success      200  {array}   models.User
+// @Failure      400  {object}  responses.ErrorResponse
+// @Failure      500  {object}  responses.ErrorResponse
+// @Failure      503  {object}  responses.ErrorResponse
+// @Failure      504  {object}  responses.ErrorResponse
+// @Router       /users/{id}/following [get]
+func (handler *UserHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	userId, err := uuid.Parse(mux.Vars(r)["id"])
+	if err != nil {
+		responses.Error(w, http.StatusBadRequest, err)
+		return
+	}
+
+	following, err := handler.users.GetFollowing(r.Context(), userId)
+	if err != nil {
+		responses.ServerError(w, r, err)
+		return
+	}
+
+	responses.JSON(w, http.StatusOK, following)
+}
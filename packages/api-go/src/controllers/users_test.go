@@ -0,0 +1,51 @@
+package controllers_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFollowUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	users := repositories.NewMockUserRepository(ctrl)
+	notifications := repositories.NewMockNotificationRepository(ctrl)
+	handler := controllers.NewUserHandler(users, notifications, repositories.NewMockSessionRepository(ctrl))
+
+	followerId := uuid.New()
+	userId := uuid.New()
+
+	users.EXPECT().Follow(gomock.Any(), followerId, userId).Return(nil)
+	notifications.EXPECT().
+		Create(gomock.Any(), models.Notification{UserId: userId, Type: models.NotificationFollow, ActorId: followerId}).
+		Return(nil)
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPost, "/api/users/"+userId.String()+"/follow", "", followerId)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.FollowUser(w, r)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+}
+
+func TestFollowYourself(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	handler := controllers.NewUserHandler(repositories.NewMockUserRepository(ctrl), repositories.NewMockNotificationRepository(ctrl), repositories.NewMockSessionRepository(ctrl))
+
+	userId := uuid.New()
+
+	w := httptest.NewRecorder()
+	r := authenticatedRequest(t, http.MethodPost, "/api/users/"+userId.String()+"/follow", "", userId)
+	r = mux.SetURLVars(r, map[string]string{"id": userId.String()})
+	handler.FollowUser(w, r)
+
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
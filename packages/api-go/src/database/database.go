@@ -3,25 +3,37 @@ package database
 import (
 	"context"
 	"database/sql"
+	"time"
 
-	"github.com/jmoiron/sqlx"
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq"
 	"github.com/otaviopontes/api-go/src/config"
+	"github.com/redis/go-redis/extra/redisotel/v9"
 	"github.com/redis/go-redis/v9"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
 )
 
+// Connect opens the connection pool shared by the whole application, sized by
+// the DB_MAX_* settings. Every query run under a traced context gets a span.
 func Connect() (*sql.DB, error) {
-	db, err := sqlx.Connect("postgres", config.ConectionString)
-
+	db, err := otelsql.Open("postgres", config.ConectionString,
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true}),
+	)
 	if err != nil {
 		return nil, err
 	}
+
+	db.SetMaxOpenConns(config.DBMaxOpenConns)
+	db.SetMaxIdleConns(config.DBMaxIdleConns)
+	db.SetConnMaxLifetime(time.Duration(config.DBConnLifetime) * time.Minute)
+
 	if err = db.Ping(); err != nil {
 		db.Close()
 		return nil, err
 	}
 
-	return db.DB, nil
+	return db, nil
 }
 
 func ConnectRedis() (*redis.Client, error) {
@@ -35,6 +47,12 @@ func ConnectRedis() (*redis.Client, error) {
 
 	_, err := client.Ping(ctx).Result()
 	if err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if err := redisotel.InstrumentTracing(client); err != nil {
+		client.Close()
 		return nil, err
 	}
 
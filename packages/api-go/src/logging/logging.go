@@ -0,0 +1,85 @@
+package logging
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"strings"
+)
+
+type contextKey struct{}
+
+// entry collects what the handlers learn about a request for its access log
+// line, written once the request is served.
+type entry struct {
+	requestId string
+	userId    string
+	err       error
+}
+
+// Setup makes the default logger write JSON lines to stdout at level, one of
+// debug, info, warn or error, so they can be shipped as they are to
+// Elasticsearch.
+func Setup(level string) {
+	var lvl slog.Level
+	if err := lvl.UnmarshalText([]byte(strings.ToUpper(level))); err != nil {
+		lvl = slog.LevelInfo
+	}
+
+	slog.SetDefault(slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: lvl})))
+}
+
+// WithRequest returns a copy of ctx carrying the log entry of the request
+// identified by requestId.
+func WithRequest(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, contextKey{}, &entry{requestId: requestId})
+}
+
+// RequestId returns the id of the request ctx belongs to, if any.
+func RequestId(ctx context.Context) string {
+	if e, ok := ctx.Value(contextKey{}).(*entry); ok {
+		return e.requestId
+	}
+	return ""
+}
+
+// SetUserId records the authenticated user of the request ctx belongs to.
+func SetUserId(ctx context.Context, userId string) {
+	if e, ok := ctx.Value(contextKey{}).(*entry); ok {
+		e.userId = userId
+	}
+}
+
+// SetError records the error that failed the request ctx belongs to.
+func SetError(ctx context.Context, err error) {
+	if e, ok := ctx.Value(contextKey{}).(*entry); ok {
+		e.err = err
+	}
+}
+
+// Attrs returns the request id, user id and error recorded for the request
+// ctx belongs to, leaving out the ones that are not set.
+func Attrs(ctx context.Context) []any {
+	e, ok := ctx.Value(contextKey{}).(*entry)
+	if !ok {
+		return nil
+	}
+
+	attrs := []any{slog.String("request_id", e.requestId)}
+	if e.userId != "" {
+		attrs = append(attrs, slog.String("user_id", e.userId))
+	}
+	if e.err != nil {
+		attrs = append(attrs, slog.String("error", e.err.Error()))
+	}
+	return attrs
+}
+
+// FromContext returns the default logger annotated with the request id of
+// ctx, for the logs written while serving a request.
+func FromContext(ctx context.Context) *slog.Logger {
+	if requestId := RequestId(ctx); requestId != "" {
+		return slog.Default().With(slog.String("request_id", requestId))
+	}
+	return slog.Default()
+}
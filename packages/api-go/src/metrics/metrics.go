@@ -0,0 +1,98 @@
+package metrics
+
+import (
+	"database/sql"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// registry holds every metric of the API along with the Go runtime and process
+// ones. It is not the global registry, so importing libraries cannot add to
+// what /metrics exposes.
+var registry = prometheus.NewRegistry()
+
+var factory = promauto.With(registry)
+
+var (
+	requests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "http_requests_total",
+		Help: "HTTP requests served, by method, route template and status code.",
+	}, []string{"method", "route", "status"})
+
+	requestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "http_request_duration_seconds",
+		Help:    "Time taken to serve HTTP requests, by method and route template.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"method", "route"})
+
+	cacheLookups = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "cache_lookups_total",
+		Help: "Redis cache lookups, by cache and result (hit or miss).",
+	}, []string{"cache", "result"})
+
+	// PostsCreated counts the posts created.
+	PostsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "posts_created_total",
+		Help: "Posts created.",
+	})
+
+	// CommentsCreated counts the comments and replies created.
+	CommentsCreated = factory.NewCounter(prometheus.CounterOpts{
+		Name: "comments_created_total",
+		Help: "Comments and replies created.",
+	})
+
+	// Reactions counts the reactions given to posts, likes included, by type.
+	Reactions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "post_reactions_total",
+		Help: "Reactions given to posts, by type.",
+	}, []string{"reaction"})
+
+	// TimelineJobsDropped counts the timeline fan-out jobs dropped because the
+	// queue was full, by kind (push, remove or rebuild).
+	TimelineJobsDropped = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "timeline_jobs_dropped_total",
+		Help: "Timeline fan-out jobs dropped because the queue was full, by kind.",
+	}, []string{"kind"})
+)
+
+func init() {
+	registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// RegisterDB exposes the connection pool stats of db. It must be called once
+// per pool.
+func RegisterDB(db *sql.DB, name string) {
+	registry.MustRegister(collectors.NewDBStatsCollector(db, name))
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(registry, promhttp.HandlerOpts{})
+}
+
+// ObserveRequest records a request served by the route matching the route
+// template, e.g. /api/posts/{id}, so the series do not grow with the ids.
+func ObserveRequest(method, route string, status int, duration time.Duration) {
+	requests.WithLabelValues(method, route, strconv.Itoa(status)).Inc()
+	requestDuration.WithLabelValues(method, route).Observe(duration.Seconds())
+}
+
+// CacheHit records a lookup of cache answered by Redis.
+func CacheHit(cache string) {
+	cacheLookups.WithLabelValues(cache, "hit").Inc()
+}
+
+// CacheMiss records a lookup of cache that fell back to the database.
+func CacheMiss(cache string) {
+	cacheLookups.WithLabelValues(cache, "miss").Inc()
+}
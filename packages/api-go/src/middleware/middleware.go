@@ -1,26 +1,200 @@
 package middlewares
 
 import (
-	"log"
+	"bufio"
+	"context"
+	"errors"
+	"log/slog"
+	"net"
 	"net/http"
+	"strings"
+	"time"
 
+	"github.com/google/uuid"
 	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/authorization"
+	"github.com/otaviopontes/api-go/src/config"
+	"github.com/otaviopontes/api-go/src/logging"
+	"github.com/otaviopontes/api-go/src/metrics"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
 	"github.com/otaviopontes/api-go/src/responses"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// Logger writes one JSON line per request once it is served, carrying the
+// request id, taken from a valid X-Request-ID header or generated, and echoed
+// back in the response. The query string is left out, as it may hold tokens.
 func Logger(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		log.Printf("\n %s %s %s", r.Method, r.RequestURI, r.Host)
-		next(w, r)
+		start := time.Now()
+
+		requestId := r.Header.Get(RequestIdHeader)
+		if !validRequestId(requestId) {
+			requestId = uuid.NewString()
+		}
+		w.Header().Set(RequestIdHeader, requestId)
+
+		ctx := logging.WithRequest(r.Context(), requestId)
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r.WithContext(ctx))
+
+		level := slog.LevelInfo
+		switch {
+		case recorder.status >= http.StatusInternalServerError:
+			level = slog.LevelError
+		case recorder.status >= http.StatusBadRequest:
+			level = slog.LevelWarn
+		}
+
+		attrs := []any{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.Int("status", recorder.status),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		}
+		if span := trace.SpanContextFromContext(ctx); span.HasTraceID() {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID().String()))
+		}
+		slog.Log(ctx, level, "request", append(attrs, logging.Attrs(ctx)...)...)
+	}
+}
+
+// RequestIdHeader carries the id correlating the logs of a request across
+// services.
+const RequestIdHeader = "X-Request-ID"
+
+// validRequestId accepts the ids a proxy or client may reasonably send, so
+// arbitrary header contents do not end up in the logs.
+func validRequestId(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range id {
+		if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.ContainsRune("-_.:", c)) {
+			return false
+		}
 	}
+	return true
 }
 
-func Authenticate(next http.HandlerFunc) http.HandlerFunc {
+// Authenticate lets through the requests bearing an access token tokens
+// verifies and sessions does not report as revoked, with its principal stored
+// in their context for authentication.PrincipalFrom.
+func Authenticate(tokens authentication.TokenVerifier, sessions repositories.SessionRepository, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		principal, err := tokens.Verify(authentication.BearerToken(r))
+		if err != nil {
+			responses.Error(w, http.StatusUnauthorized, err)
+			return
+		}
+		logging.SetUserId(r.Context(), principal.UserId.String())
+
+		revoked, err := sessions.IsRevoked(r.Context(), principal.UserId, principal.TokenId, principal.IssuedAt)
+		if err != nil {
+			responses.ServerError(w, r, err)
+			return
+		}
+		if revoked {
+			responses.Error(w, http.StatusUnauthorized, errTokenRevoked)
+			return
+		}
+
+		next(w, r.WithContext(authentication.WithPrincipal(r.Context(), principal)))
+	}
+}
+
+var errTokenRevoked = errors.New("the token was revoked, sign in again")
+
+// RequireRole lets through the authenticated requests of the users whose role
+// includes role. It must run after Authenticate.
+func RequireRole(role models.Role, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
-		if err := authentication.ValidateToken(r); err != nil {
+		err := authorization.RequireRole(r.Context(), role)
+		if errors.Is(err, authentication.ErrUnauthenticated) {
 			responses.Error(w, http.StatusUnauthorized, err)
 			return
 		}
+		if err != nil {
+			responses.Error(w, http.StatusForbidden, err)
+			return
+		}
+
 		next(w, r)
 	}
 }
+
+// Timeout bounds the request context by config.RequestTimeout, so the queries
+// of a slow request, or of one whose client went away, are canceled.
+func Timeout(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), time.Duration(config.RequestTimeout)*time.Second)
+		defer cancel()
+
+		next(w, r.WithContext(ctx))
+	}
+}
+
+// Metrics records the status and duration of the requests served by the
+// route whose template is route.
+func Metrics(route string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next(recorder, r)
+
+		metrics.ObserveRequest(r.Method, route, recorder.status, time.Since(start))
+	}
+}
+
+// statusRecorder keeps the status code written through it. It lets the live
+// routes flush and hijack the connection underneath.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (recorder *statusRecorder) WriteHeader(status int) {
+	if !recorder.wroteHeader {
+		recorder.status = status
+		recorder.wroteHeader = true
+	}
+	recorder.ResponseWriter.WriteHeader(status)
+}
+
+func (recorder *statusRecorder) Write(data []byte) (int, error) {
+	recorder.wroteHeader = true
+	written, err := recorder.ResponseWriter.Write(data)
+	recorder.bytes += int64(written)
+	return written, err
+}
+
+func (recorder *statusRecorder) Flush() {
+	if flusher, ok := recorder.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (recorder *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := recorder.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("the response cannot be hijacked")
+	}
+	conn, buffer, err := hijacker.Hijack()
+	if err == nil {
+		recorder.status = http.StatusSwitchingProtocols
+	}
+	return conn, buffer, err
+}
+
+// Unwrap gives http.ResponseController access to the underlying writer.
+func (recorder *statusRecorder) Unwrap() http.ResponseWriter {
+	return recorder.ResponseWriter
+}
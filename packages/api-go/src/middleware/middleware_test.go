@@ -0,0 +1,103 @@
+package middlewares_test
+
+import (
+	"crypto/ed25519"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/authentication"
+	"github.com/otaviopontes/api-go/src/logging"
+	middlewares "github.com/otaviopontes/api-go/src/middleware"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoggerKeepsRequestId(t *testing.T) {
+	var seen string
+	handler := middlewares.Logger(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestId(r.Context())
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+	r.Header.Set(middlewares.RequestIdHeader, "edge-42")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.Equal(t, "edge-42", seen)
+	assert.Equal(t, "edge-42", w.Header().Get(middlewares.RequestIdHeader))
+}
+
+func TestLoggerReplacesInvalidRequestId(t *testing.T) {
+	var seen string
+	handler := middlewares.Logger(func(w http.ResponseWriter, r *http.Request) {
+		seen = logging.RequestId(r.Context())
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+	r.Header.Set(middlewares.RequestIdHeader, "bad\nid")
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.NotEqual(t, "bad\nid", seen)
+	assert.Len(t, seen, 36)
+	assert.Equal(t, seen, w.Header().Get(middlewares.RequestIdHeader))
+}
+
+func TestAuthenticateStoresPrincipal(t *testing.T) {
+	_, private, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	keys, err := authentication.NewKeySet(private)
+	assert.NoError(t, err)
+	tokens, err := authentication.NewTokens(keys, authentication.TokenOptions{
+		Issuer:     "test",
+		Audience:   "test",
+		TTL:        time.Minute,
+		Algorithms: []string{"EdDSA"},
+	})
+	assert.NoError(t, err)
+
+	userId := uuid.New()
+	token, err := tokens.Issue(userId, models.RoleUser)
+	assert.NoError(t, err)
+
+	ctrl := gomock.NewController(t)
+	sessions := repositories.NewMockSessionRepository(ctrl)
+	sessions.EXPECT().IsRevoked(gomock.Any(), userId, gomock.Any(), gomock.Any()).Return(false, nil)
+
+	var seen uuid.UUID
+	handler := middlewares.Authenticate(tokens, sessions, func(w http.ResponseWriter, r *http.Request) {
+		seen, err = authentication.UserIdFrom(r.Context())
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/api/posts", nil)
+	r.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	handler(w, r)
+
+	assert.NoError(t, err)
+	assert.Equal(t, userId, seen)
+}
+
+func TestRequireRole(t *testing.T) {
+	handler := middlewares.RequireRole(models.RoleModerator, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	for role, status := range map[models.Role]int{
+		models.RoleUser:      http.StatusForbidden,
+		models.RoleModerator: http.StatusNoContent,
+		models.RoleAdmin:     http.StatusNoContent,
+	} {
+		r := httptest.NewRequest(http.MethodPost, "/api/users/1/suspension", nil)
+		r = r.WithContext(authentication.WithPrincipal(r.Context(), authentication.Principal{UserId: uuid.New(), Role: role}))
+		w := httptest.NewRecorder()
+		handler(w, r)
+
+		assert.Equal(t, status, w.Code, role)
+	}
+}
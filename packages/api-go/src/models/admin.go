@@ -0,0 +1,59 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// UserFilter narrows the users listed by the admin API. Zero fields match
+// every user.
+type UserFilter struct {
+	// Query is matched against the nick, name and email, case insensitively.
+	Query     string
+	Role      Role
+	Suspended *bool
+}
+
+type UserPage struct {
+	Users      []User `json:"users"`
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// Stats are the aggregated counts shown to the admins.
+type Stats struct {
+	Users          uint64          `json:"users"`
+	UsersByRole    map[Role]uint64 `json:"usersByRole"`
+	SuspendedUsers uint64          `json:"suspendedUsers"`
+	Posts          uint64          `json:"posts"`
+	PostsLastDay   uint64          `json:"postsLastDay"`
+	Comments       uint64          `json:"comments"`
+	Reactions      uint64          `json:"reactions"`
+	Follows        uint64          `json:"follows"`
+}
+
+type AuditTarget string
+
+const (
+	AuditTargetUser    AuditTarget = "user"
+	AuditTargetPost    AuditTarget = "post"
+	AuditTargetComment AuditTarget = "comment"
+)
+
+// AuditEntry records an action an admin or moderator took, on what, and with
+// which details. Entries outlive the users and posts they reference.
+type AuditEntry struct {
+	Id         uuid.UUID       `json:"id"`
+	ActorId    uuid.UUID       `json:"actorId"`
+	Action     string          `json:"action"`
+	TargetType AuditTarget     `json:"targetType"`
+	TargetId   uuid.UUID       `json:"targetId"`
+	Details    json.RawMessage `json:"details,omitempty" swaggertype:"object"`
+	CreatedAt  time.Time       `json:"createdAt"`
+}
+
+type AuditPage struct {
+	Entries    []AuditEntry `json:"entries"`
+	NextCursor string       `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+type Comment struct {
+	Id           uuid.UUID  `json:"id,omitempty"`
+	PostId       uuid.UUID  `json:"postId,omitempty"`
+	ParentId     *uuid.UUID `json:"parentId,omitempty"`
+	AuthorId     uuid.UUID  `json:"authorId,omitempty"`
+	AuthorNick   string     `json:"authorNick,omitempty"`
+	Content      string     `json:"content,omitempty"`
+	RepliesCount uint64     `json:"repliesCount"`
+	CreatedAt    time.Time  `json:"createdAt,omitempty"`
+	UpdatedAt    *time.Time `json:"updatedAt,omitempty"`
+}
+
+type CommentPage struct {
+	Comments   []Comment `json:"comments"`
+	NextCursor string    `json:"nextCursor,omitempty"`
+}
+
+func (comment *Comment) Prepare() error {
+
+	if err := comment.validate(); err != nil {
+		return err
+	}
+	comment.format()
+	return nil
+}
+
+func (comment *Comment) validate() error {
+	if strings.TrimSpace(comment.Content) == "" {
+		return errors.New("the content is mandatory and cannot be left blank")
+	}
+	if utf8.RuneCountInString(strings.TrimSpace(comment.Content)) > 300 {
+		return errors.New("the content cannot be longer than 300 characters")
+	}
+	return nil
+}
+
+func (comment *Comment) format() {
+	comment.Content = strings.TrimSpace(comment.Content)
+}
@@ -0,0 +1,17 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPrepareCommentCountsCharacters(t *testing.T) {
+	comment := models.Comment{Content: strings.Repeat("é", 300)}
+	assert.NoError(t, comment.Prepare())
+
+	comment = models.Comment{Content: strings.Repeat("é", 301)}
+	assert.Error(t, comment.Prepare())
+}
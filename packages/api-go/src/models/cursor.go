@@ -0,0 +1,54 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Cursor points at the last item of a page ordered by (createdAt, id). Clients
+// only ever see it as an opaque string.
+type Cursor struct {
+	CreatedAt time.Time
+	Id        uuid.UUID
+}
+
+func (cursor Cursor) IsZero() bool {
+	return cursor.Id == uuid.Nil
+}
+
+func (cursor Cursor) Encode() string {
+	raw := cursor.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + cursor.Id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(encoded string) (Cursor, error) {
+	if encoded == "" {
+		return Cursor{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Cursor{}, errors.New("the cursor is invalid")
+	}
+
+	parts := strings.Split(string(raw), "|")
+	if len(parts) != 2 {
+		return Cursor{}, errors.New("the cursor is invalid")
+	}
+
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return Cursor{}, errors.New("the cursor is invalid")
+	}
+
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return Cursor{}, errors.New("the cursor is invalid")
+	}
+
+	return Cursor{CreatedAt: createdAt, Id: id}, nil
+}
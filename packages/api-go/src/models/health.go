@@ -0,0 +1,18 @@
+package models
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// DependencyHealth is the outcome of pinging one dependency.
+type DependencyHealth struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	Error     string  `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status       string                      `json:"status"`
+	Dependencies map[string]DependencyHealth `json:"dependencies,omitempty"`
+}
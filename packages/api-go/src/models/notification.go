@@ -0,0 +1,54 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type NotificationType string
+
+const (
+	NotificationReaction NotificationType = "reaction"
+	NotificationComment  NotificationType = "comment"
+	NotificationFollow   NotificationType = "follow"
+	NotificationMention  NotificationType = "mention"
+)
+
+type Notification struct {
+	Id        uuid.UUID        `json:"id,omitempty"`
+	UserId    uuid.UUID        `json:"userId,omitempty"`
+	Type      NotificationType `json:"type"`
+	ActorId   uuid.UUID        `json:"actorId,omitempty"`
+	ActorNick string           `json:"actorNick,omitempty"`
+	PostId    *uuid.UUID       `json:"postId,omitempty"`
+	CommentId *uuid.UUID       `json:"commentId,omitempty"`
+	Reaction  Reaction         `json:"reaction,omitempty"`
+	Read      bool             `json:"read"`
+	CreatedAt time.Time        `json:"createdAt,omitempty"`
+}
+
+type NotificationPage struct {
+	Notifications []Notification `json:"notifications"`
+	Unread        uint64         `json:"unread"`
+	NextCursor    string         `json:"nextCursor,omitempty"`
+}
+
+// MentionNotifications returns the notifications owed to the users mentioned in
+// post. Mentions that were not resolved to a user are skipped.
+func MentionNotifications(post Post) []Notification {
+	notifications := []Notification{}
+	for _, mention := range post.Mentions {
+		if mention.UserId == uuid.Nil {
+			continue
+		}
+		postId := post.Id
+		notifications = append(notifications, Notification{
+			UserId:  mention.UserId,
+			Type:    NotificationMention,
+			ActorId: post.AuthorId,
+			PostId:  &postId,
+		})
+	}
+	return notifications
+}
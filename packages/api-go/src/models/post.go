@@ -2,20 +2,66 @@ package models
 
 import (
 	"errors"
+	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/google/uuid"
 )
 
 type Post struct {
-	Id         uuid.UUID `json:"id,omitempty"`
-	Title      string    `json:"title,omitempty"`
-	Content    string    `json:"content,omitempty"`
-	AuthorId   uuid.UUID `json:"authorId,omitempty"`
-	AuthorNick string    `json:"authorNick,omitempty"`
-	Likes      uint64    `json:"likes"`
-	CreatedAt  time.Time `json:"createdAt,omitempty"`
+	Id            uuid.UUID           `json:"id,omitempty"`
+	Title         string              `json:"title,omitempty"`
+	Content       string              `json:"content,omitempty"`
+	AuthorId      uuid.UUID           `json:"authorId,omitempty"`
+	AuthorNick    string              `json:"authorNick,omitempty"`
+	Likes         uint64              `json:"likes"`
+	LikedByMe     bool                `json:"likedByMe"`
+	Reactions     map[Reaction]uint64 `json:"reactions"`
+	MyReaction    Reaction            `json:"myReaction,omitempty"`
+	CommentsCount uint64              `json:"commentsCount"`
+	Tags          []string            `json:"tags"`
+	Mentions      []Mention           `json:"mentions"`
+	CreatedAt     time.Time           `json:"createdAt,omitempty"`
+}
+
+// tagPattern matches a #tag that starts the content or follows a character
+// that cannot be part of a tag, so URL fragments such as page#section are ignored.
+// It takes the whole tag, so ExtractTags can skip the ones longer than
+// maxTagLength instead of storing their prefix as a different tag.
+var tagPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])#([\p{L}\p{N}_]+)`)
+
+// maxTagLength is the longest tag, in characters, that post_tags holds.
+const maxTagLength = 50
+
+// mentionPattern matches an @nick under the same boundary rule as tagPattern,
+// which also keeps e-mail addresses from being read as mentions. Like
+// tagPattern, it takes the whole nick, so ExtractMentions can skip the ones
+// longer than maxNickLength instead of mentioning the user owning their prefix.
+var mentionPattern = regexp.MustCompile(`(?:^|[^\p{L}\p{N}_])(@([\p{L}\p{N}_]+))`)
+
+// maxNickLength is the longest nick, in characters, that users holds.
+const maxNickLength = 50
+
+// Mention is an @nick found in the content of a post. Offset and Length are
+// counted in characters and cover the leading @. UserId is only set once the
+// nick has been resolved to a user.
+type Mention struct {
+	UserId uuid.UUID `json:"userId"`
+	Nick   string    `json:"nick"`
+	Offset int       `json:"offset"`
+	Length int       `json:"length"`
+}
+
+type TrendingTag struct {
+	Tag   string `json:"tag"`
+	Count uint64 `json:"count"`
+}
+
+type PostPage struct {
+	Posts      []Post `json:"posts"`
+	NextCursor string `json:"nextCursor,omitempty"`
 }
 
 func (post *Post) Prepare() error {
@@ -40,4 +86,48 @@ func (post *Post) validate() error {
 func (post *Post) format() {
 	post.Title = strings.TrimSpace(post.Title)
 	post.Content = strings.TrimSpace(post.Content)
+	post.Tags = ExtractTags(post.Content)
+	post.Mentions = ExtractMentions(post.Content)
+}
+
+// ExtractTags returns the distinct #tags of content, lowercased and without
+// the leading #, in the order they first appear. Tags longer than maxTagLength
+// are skipped.
+func ExtractTags(content string) []string {
+	tags := []string{}
+	seen := make(map[string]bool)
+
+	for _, match := range tagPattern.FindAllStringSubmatch(content, -1) {
+		tag := strings.ToLower(match[1])
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			continue
+		}
+		if !seen[tag] {
+			seen[tag] = true
+			tags = append(tags, tag)
+		}
+	}
+
+	return tags
+}
+
+// ExtractMentions returns every @nick of content, in order, without resolving
+// them to users. Nicks longer than maxNickLength are skipped.
+func ExtractMentions(content string) []Mention {
+	mentions := []Mention{}
+
+	for _, match := range mentionPattern.FindAllStringSubmatchIndex(content, -1) {
+		start, end := match[2], match[3]
+		nick := content[match[4]:match[5]]
+		if utf8.RuneCountInString(nick) > maxNickLength {
+			continue
+		}
+		mentions = append(mentions, Mention{
+			Nick:   nick,
+			Offset: utf8.RuneCountInString(content[:start]),
+			Length: utf8.RuneCountInString(content[start:end]),
+		})
+	}
+
+	return mentions
 }
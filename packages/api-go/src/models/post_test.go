@@ -0,0 +1,57 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExtractTags(t *testing.T) {
+	tests := []struct {
+		content string
+		tags    []string
+	}{
+		{"no tags here", []string{}},
+		{"#Go is #fun", []string{"go", "fun"}},
+		{"repeated #go and (#GO)", []string{"go"}},
+		{"see example.com/page#section", []string{}},
+		{"accents work: #café_2", []string{"café_2"}},
+		{"#" + strings.Repeat("a", 50) + " fits", []string{strings.Repeat("a", 50)}},
+		{"#" + strings.Repeat("a", 51) + " is too long, #go", []string{"go"}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.tags, models.ExtractTags(test.content), test.content)
+	}
+}
+
+func TestPreparePostExtractsTags(t *testing.T) {
+	post := models.Post{Title: " Title ", Content: " Loving #Redis "}
+
+	assert.NoError(t, post.Prepare())
+	assert.Equal(t, "Loving #Redis", post.Content)
+	assert.Equal(t, []string{"redis"}, post.Tags)
+}
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		content  string
+		mentions []models.Mention
+	}{
+		{"nobody here", []models.Mention{}},
+		{"@alice and @bob_2.", []models.Mention{
+			{Nick: "alice", Offset: 0, Length: 6},
+			{Nick: "bob_2", Offset: 11, Length: 6},
+		}},
+		{"mail me at someone@example.com", []models.Mention{}},
+		{"olá @joão", []models.Mention{{Nick: "joão", Offset: 4, Length: 5}}},
+		{"@" + strings.Repeat("a", 50) + " fits", []models.Mention{{Nick: strings.Repeat("a", 50), Offset: 0, Length: 51}}},
+		{"@" + strings.Repeat("a", 51) + " is too long, @bob", []models.Mention{{Nick: "bob", Offset: 66, Length: 4}}},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.mentions, models.ExtractMentions(test.content), test.content)
+	}
+}
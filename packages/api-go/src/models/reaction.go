@@ -0,0 +1,21 @@
+package models
+
+import "errors"
+
+type Reaction string
+
+const (
+	ReactionLike  Reaction = "like"
+	ReactionLove  Reaction = "love"
+	ReactionLaugh Reaction = "laugh"
+	ReactionSad   Reaction = "sad"
+	ReactionAngry Reaction = "angry"
+)
+
+func (reaction Reaction) Validate() error {
+	switch reaction {
+	case ReactionLike, ReactionLove, ReactionLaugh, ReactionSad, ReactionAngry:
+		return nil
+	}
+	return errors.New("the reaction must be one of like, love, laugh, sad or angry")
+}
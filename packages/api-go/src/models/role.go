@@ -0,0 +1,39 @@
+package models
+
+import "fmt"
+
+// Role is what a user is allowed to do beyond managing their own account and
+// content. Each role includes the ones below it.
+type Role string
+
+const (
+	RoleUser      Role = "user"
+	RoleModerator Role = "moderator"
+	RoleAdmin     Role = "admin"
+)
+
+var roleRanks = map[Role]int{
+	RoleUser:      1,
+	RoleModerator: 2,
+	RoleAdmin:     3,
+}
+
+// ParseRole returns the role named name.
+func ParseRole(name string) (Role, error) {
+	role := Role(name)
+	if _, ok := roleRanks[role]; !ok {
+		return "", fmt.Errorf("unknown role %q", name)
+	}
+	return role, nil
+}
+
+// Includes reports whether role grants everything other does.
+func (role Role) Includes(other Role) bool {
+	return roleRanks[role] >= roleRanks[other] && roleRanks[role] > 0
+}
+
+// Outranks reports whether role is strictly above other, as needed to
+// moderate the accounts of other.
+func (role Role) Outranks(other Role) bool {
+	return roleRanks[role] > roleRanks[other]
+}
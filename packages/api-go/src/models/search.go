@@ -0,0 +1,7 @@
+package models
+
+type SearchResult struct {
+	Posts      []Post `json:"posts,omitempty"`
+	Users      []User `json:"users,omitempty"`
+	NextOffset int    `json:"nextOffset,omitempty"`
+}
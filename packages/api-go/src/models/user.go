@@ -16,7 +16,13 @@ type User struct {
 	Nick      string    `json:"nick"`
 	Email     string    `json:"email"`
 	Password  string    `json:"password,omitempty"`
-	CreatedAt time.Time `json:"created_at,omitempty"`
+	Followers uint64    `json:"followers"`
+	Following uint64    `json:"following"`
+	// Role and SuspendedAt are only changed by moderation, never through the
+	// account endpoints.
+	Role        Role       `json:"role,omitempty"`
+	SuspendedAt *time.Time `json:"suspended_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at,omitempty"`
 }
 
 func (user *User) Prepare(isRegister bool) error {
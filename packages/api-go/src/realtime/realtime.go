@@ -0,0 +1,252 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// Channel is the Redis pub/sub channel every instance publishes its events
+	// to and relays to its own subscribers, so a client receives the events of
+	// every instance whichever one it is connected to.
+	Channel = "events:posts"
+	// StreamKey is the Redis stream keeping the latest events, whose entry ids
+	// are the event ids, so reconnecting clients can resume where they left.
+	StreamKey = "events:posts:stream"
+)
+
+const (
+	PostCreated = "post.created"
+	PostUpdated = "post.updated"
+	PostDeleted = "post.deleted"
+	PostLiked   = "post.liked"
+)
+
+// StreamSize caps StreamKey. Clients resuming from an older event miss the
+// events trimmed since.
+var StreamSize int64 = 1000
+
+// sendBuffer bounds the events queued for a subscriber. A subscriber that falls
+// further behind is disconnected rather than slowing the others.
+const sendBuffer = 64
+
+// publishScript appends the event to the stream and publishes it prefixed by
+// its entry id in one step, so live subscribers see the events in stream order.
+var publishScript = redis.NewScript(`
+local id = redis.call('XADD', KEYS[1], 'MAXLEN', '~', ARGV[1], '*', 'event', ARGV[2])
+redis.call('PUBLISH', KEYS[2], id .. '\n' .. ARGV[2])
+return id
+`)
+
+type Event struct {
+	Id   string          `json:"id,omitempty"`
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// PostRef is the data of a PostDeleted event.
+type PostRef struct {
+	Id uuid.UUID `json:"id"`
+}
+
+// ReactionChange is the data of a PostLiked event, published whenever a user
+// reacts to a post or removes their reaction, in which case Removed is set.
+type ReactionChange struct {
+	PostId   uuid.UUID       `json:"postId"`
+	UserId   uuid.UUID       `json:"userId"`
+	Reaction models.Reaction `json:"reaction"`
+	Removed  bool            `json:"removed,omitempty"`
+}
+
+type subscriber struct {
+	events chan Event
+}
+
+type hub struct {
+	redis       *redis.Client
+	pubsub      *redis.PubSub
+	subscribers map[*subscriber]bool
+	register    chan *subscriber
+	unregister  chan *subscriber
+	done        chan struct{}
+	wg          sync.WaitGroup
+}
+
+var (
+	mutex   sync.RWMutex
+	current *hub
+)
+
+// Start subscribes to Channel and launches the hub goroutine that relays its
+// events to the WebSocket and event stream subscribers. Until it is called,
+// and after Stop, Publish is a no-op and subscribers are turned away.
+func Start(redis *redis.Client) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	current = &hub{
+		redis:       redis,
+		pubsub:      redis.Subscribe(context.Background(), Channel),
+		subscribers: make(map[*subscriber]bool),
+		register:    make(chan *subscriber),
+		unregister:  make(chan *subscriber),
+		done:        make(chan struct{}),
+	}
+	current.wg.Add(1)
+	go current.run()
+}
+
+// Stop unsubscribes from Channel and disconnects every subscriber.
+func Stop() {
+	mutex.Lock()
+	h := current
+	current = nil
+	mutex.Unlock()
+
+	if h == nil {
+		return
+	}
+
+	close(h.done)
+	h.pubsub.Close()
+	h.wg.Wait()
+}
+
+// Publish records an event in the stream and broadcasts it to the subscribers
+// of every instance.
+func Publish(eventType string, data interface{}) {
+	h := running()
+	if h == nil {
+		return
+	}
+
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		slog.Error("encoding event failed", slog.String("type", eventType), slog.String("error", err.Error()))
+		return
+	}
+
+	payload, _ := json.Marshal(Event{Type: eventType, Data: encoded})
+
+	err = publishScript.Run(context.Background(), h.redis, []string{StreamKey, Channel}, StreamSize, payload).Err()
+	if err != nil {
+		slog.Error("publishing event failed", slog.String("type", eventType), slog.String("error", err.Error()))
+	}
+}
+
+func running() *hub {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	return current
+}
+
+// subscribe registers a new subscriber, or returns nil when the hub stopped.
+// The caller must pass it to unsubscribe once done.
+func (h *hub) subscribe() *subscriber {
+	s := &subscriber{events: make(chan Event, sendBuffer)}
+
+	select {
+	case h.register <- s:
+		return s
+	case <-h.done:
+		return nil
+	}
+}
+
+func (h *hub) unsubscribe(s *subscriber) {
+	select {
+	case h.unregister <- s:
+	case <-h.done:
+	}
+}
+
+func (h *hub) run() {
+	defer h.wg.Done()
+
+	messages := h.pubsub.Channel()
+
+	for {
+		select {
+		case s := <-h.register:
+			h.subscribers[s] = true
+
+		case s := <-h.unregister:
+			h.drop(s)
+
+		case message, ok := <-messages:
+			if !ok {
+				messages = nil
+				continue
+			}
+
+			event, err := decode(message.Payload)
+			if err != nil {
+				slog.Error("decoding event failed", slog.String("error", err.Error()))
+				continue
+			}
+
+			for s := range h.subscribers {
+				select {
+				case s.events <- event:
+				default:
+					h.drop(s)
+				}
+			}
+
+		case <-h.done:
+			for s := range h.subscribers {
+				h.drop(s)
+			}
+			return
+		}
+	}
+}
+
+// drop forgets s and closes its queue, which tells its connection to close.
+func (h *hub) drop(s *subscriber) {
+	if h.subscribers[s] {
+		delete(h.subscribers, s)
+		close(s.events)
+	}
+}
+
+// decode parses a message published by publishScript.
+func decode(message string) (Event, error) {
+	var event Event
+
+	id, payload, _ := strings.Cut(message, "\n")
+	if err := json.Unmarshal([]byte(payload), &event); err != nil {
+		return Event{}, err
+	}
+	event.Id = id
+
+	return event, nil
+}
+
+// after reports whether the stream entry id a comes after b. Malformed ids
+// sort first.
+func after(a, b string) bool {
+	aMillis, aSeq := splitId(a)
+	bMillis, bSeq := splitId(b)
+
+	if aMillis != bMillis {
+		return aMillis > bMillis
+	}
+	return aSeq > bSeq
+}
+
+func splitId(id string) (uint64, uint64) {
+	rawMillis, rawSeq, _ := strings.Cut(id, "-")
+	millis, _ := strconv.ParseUint(rawMillis, 10, 64)
+	seq, _ := strconv.ParseUint(rawSeq, 10, 64)
+	return millis, seq
+}
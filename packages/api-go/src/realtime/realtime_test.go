@@ -0,0 +1,33 @@
+package realtime
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecode(t *testing.T) {
+	event, err := decode("1700000000000-3\n" + `{"type":"post.deleted","data":{"id":"3f1c"}}`)
+	assert.NoError(t, err)
+	assert.Equal(t, Event{Id: "1700000000000-3", Type: PostDeleted, Data: json.RawMessage(`{"id":"3f1c"}`)}, event)
+
+	_, err = decode("1700000000000-3\nnot json")
+	assert.Error(t, err)
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		a, b  string
+		after bool
+	}{
+		{"1700000000001-0", "1700000000000-9", true},
+		{"1700000000000-10", "1700000000000-9", true},
+		{"1700000000000-9", "1700000000000-9", false},
+		{"999-0", "1700000000000-0", false},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.after, after(test.a, test.b), "%s after %s", test.a, test.b)
+	}
+}
@@ -0,0 +1,126 @@
+package realtime
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	// keepAlivePeriod is how often a comment is written to idle event streams,
+	// so proxies do not close them.
+	keepAlivePeriod = 30 * time.Second
+	// retryDelay is the reconnection delay suggested to EventSource clients.
+	retryDelay = 3 * time.Second
+)
+
+var (
+	ErrStopped        = errors.New("live updates are not available")
+	ErrInvalidEventId = errors.New("the last event id is invalid")
+	ErrNotStreamable  = errors.New("the response cannot be streamed")
+)
+
+var eventIdPattern = regexp.MustCompile(`^\d+-\d+$`)
+
+// Stream serves the events to w in the text/event-stream format until the
+// request is done or the hub stops. When lastEventId is set, the events kept
+// in StreamKey after it are replayed first. Errors are only returned before
+// anything is written, so the caller can still answer with an error.
+func Stream(w http.ResponseWriter, r *http.Request, lastEventId string) error {
+	if lastEventId != "" && !eventIdPattern.MatchString(lastEventId) {
+		return ErrInvalidEventId
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return ErrNotStreamable
+	}
+
+	h := running()
+	if h == nil {
+		return ErrStopped
+	}
+
+	// Subscribing before reading the backlog ensures no event falls between
+	// the two; the ones seen in both are skipped by id.
+	s := h.subscribe()
+	if s == nil {
+		return ErrStopped
+	}
+	defer h.unsubscribe(s)
+
+	// The stream lasts as long as the client listens, past the server write
+	// timeout. Should the writer not support lifting it, the client resumes
+	// from its last event once the connection is cut.
+	http.NewResponseController(w).SetWriteDeadline(time.Time{})
+
+	var backlog []redis.XMessage
+	if lastEventId != "" {
+		var err error
+		backlog, err = h.redis.XRange(r.Context(), StreamKey, "("+lastEventId, "+").Result()
+		if err != nil {
+			return err
+		}
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprintf(w, "retry: %d\n\n", retryDelay.Milliseconds())
+
+	last := lastEventId
+	for _, message := range backlog {
+		payload, _ := message.Values["event"].(string)
+		event, err := decode(message.ID + "\n" + payload)
+		if err != nil {
+			continue
+		}
+		if writeEvent(w, event) != nil {
+			return nil
+		}
+		last = event.Id
+	}
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlivePeriod)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return nil
+
+		case event, ok := <-s.events:
+			if !ok {
+				return nil
+			}
+			if last != "" && !after(event.Id, last) {
+				continue
+			}
+			if writeEvent(w, event) != nil {
+				return nil
+			}
+			flusher.Flush()
+			last = event.Id
+
+		case <-ticker.C:
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return nil
+			}
+			flusher.Flush()
+		}
+	}
+}
+
+func writeEvent(w io.Writer, event Event) error {
+	_, err := fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.Id, event.Type, event.Data)
+	return err
+}
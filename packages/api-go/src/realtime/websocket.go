@@ -0,0 +1,84 @@
+package realtime
+
+import (
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait  = 10 * time.Second
+	pongWait   = 60 * time.Second
+	pingPeriod = pongWait * 9 / 10
+	// maxMessageSize bounds what WebSocket subscribers may send; they are only
+	// expected to answer pings and close the connection.
+	maxMessageSize = 512
+)
+
+// Serve relays the events to conn as JSON messages until the subscriber
+// disconnects or the hub stops. It blocks for the lifetime of the connection.
+func Serve(conn *websocket.Conn) {
+	h := running()
+	if h == nil {
+		conn.Close()
+		return
+	}
+
+	s := h.subscribe()
+	if s == nil {
+		conn.Close()
+		return
+	}
+
+	go write(conn, s)
+	read(conn)
+
+	h.unsubscribe(s)
+}
+
+// read discards whatever the subscriber sends, keeping the read deadline
+// alive on pongs, and returns once the connection is closed.
+func read(conn *websocket.Conn) {
+	defer conn.Close()
+
+	conn.SetReadLimit(maxMessageSize)
+	conn.SetReadDeadline(time.Now().Add(pongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+	}
+}
+
+// write sends the queued events and periodic pings to the subscriber.
+func write(conn *websocket.Conn, s *subscriber) {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		conn.Close()
+	}()
+
+	for {
+		select {
+		case event, ok := <-s.events:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, []byte{})
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+
+		case <-ticker.C:
+			conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+type AuditRepository interface {
+	Record(ctx context.Context, entry models.AuditEntry, action func(ctx context.Context) error) error
+	List(ctx context.Context, targetId uuid.UUID, cursor models.Cursor, limit int) (models.AuditPage, error)
+}
+
+type Audit struct {
+	db *sql.DB
+}
+
+func NewAuditRepository(db *sql.DB) *Audit {
+	return &Audit{db}
+}
+
+// Record runs action and adds entry to the audit trail in one transaction, so
+// neither is committed without the other. The repository methods action calls
+// with its ctx join the transaction, and their cache invalidations and fan-out
+// only happen once it is committed.
+func (repository Audit) Record(ctx context.Context, entry models.AuditEntry, action func(ctx context.Context) error) error {
+	var details []byte
+	if len(entry.Details) > 0 {
+		details = entry.Details
+	}
+
+	return inTransaction(ctx, repository.db, func(ctx context.Context) error {
+		if err := action(ctx); err != nil {
+			return err
+		}
+
+		_, err := conn(ctx, repository.db).ExecContext(ctx,
+			"insert into audit_log (actor_id, action, target_type, target_id, details) values ($1, $2, $3, $4, $5)",
+			entry.ActorId, entry.Action, entry.TargetType, entry.TargetId, details,
+		)
+		return err
+	})
+}
+
+// List returns a page of the audit entries older than cursor, newest first,
+// only the ones about targetId unless it is uuid.Nil.
+func (repository Audit) List(ctx context.Context, targetId uuid.UUID, cursor models.Cursor, limit int) (models.AuditPage, error) {
+	args := []interface{}{}
+	query := `
+	select id, actor_id, action, target_type, target_id, details, createdAt
+	from audit_log
+	where true`
+	if targetId != uuid.Nil {
+		args = append(args, targetId)
+		query += fmt.Sprintf(" and target_id = $%d", len(args))
+	}
+	if !cursor.IsZero() {
+		args = append(args, cursor.CreatedAt, cursor.Id)
+		query += fmt.Sprintf(" and (createdAt, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	query += fmt.Sprintf("\n\torder by createdAt desc, id desc\n\tlimit $%d", len(args))
+
+	lines, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.AuditPage{}, err
+	}
+
+	defer lines.Close()
+
+	entries := []models.AuditEntry{}
+	for lines.Next() {
+		var entry models.AuditEntry
+		var details []byte
+
+		if err := lines.Scan(
+			&entry.Id,
+			&entry.ActorId,
+			&entry.Action,
+			&entry.TargetType,
+			&entry.TargetId,
+			&details,
+			&entry.CreatedAt,
+		); err != nil {
+			return models.AuditPage{}, err
+		}
+
+		entry.Details = details
+		entries = append(entries, entry)
+	}
+
+	if err := lines.Err(); err != nil {
+		return models.AuditPage{}, err
+	}
+
+	page := models.AuditPage{Entries: entries}
+	if len(entries) > limit {
+		page.Entries = entries[:limit]
+		last := page.Entries[limit-1]
+		page.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+	}
+
+	return page, nil
+}
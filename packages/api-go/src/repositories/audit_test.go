@@ -0,0 +1,147 @@
+package repositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordAuditEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	auditRepo := repositories.NewAuditRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+
+	userId := uuid.New()
+	entry := models.AuditEntry{
+		ActorId:    uuid.New(),
+		Action:     "users:suspend",
+		TargetType: models.AuditTargetUser,
+		TargetId:   userId,
+		Details:    []byte(`{"reason": "spam"}`),
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("update users set suspendedAt").
+		WithArgs(userId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("insert into audit_log").
+		WithArgs(entry.ActorId, entry.Action, entry.TargetType, entry.TargetId, []byte(`{"reason": "spam"}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	err = auditRepo.Record(context.Background(), entry, func(ctx context.Context) error {
+		return userRepo.Suspend(ctx, userId)
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAuditEntryFailureRollsBackTheAction(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	auditRepo := repositories.NewAuditRepository(db)
+	userRepo := repositories.NewUserRepository(db)
+
+	userId := uuid.New()
+	entry := models.AuditEntry{
+		ActorId:    uuid.New(),
+		Action:     "users:suspend",
+		TargetType: models.AuditTargetUser,
+		TargetId:   userId,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("update users set suspendedAt").
+		WithArgs(userId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("insert into audit_log").
+		WillReturnError(errors.New("pq: canceling statement due to user request"))
+	mock.ExpectRollback()
+
+	err = auditRepo.Record(context.Background(), entry, func(ctx context.Context) error {
+		return userRepo.Suspend(ctx, userId)
+	})
+
+	assert.Error(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRecordAuditEntryInvalidatesAfterCommit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	auditRepo := repositories.NewAuditRepository(db)
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	postId := uuid.New()
+	entry := models.AuditEntry{
+		ActorId:    uuid.New(),
+		Action:     "posts:delete",
+		TargetType: models.AuditTargetPost,
+		TargetId:   postId,
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectPrepare("delete from posts where id").
+		ExpectQuery().
+		WithArgs(postId).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(uuid.New()))
+	mock.ExpectExec("insert into audit_log").
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	redisMock.ExpectIncr("posts:generation").SetVal(1)
+	redisMock.ExpectDel("posts").SetVal(1)
+
+	err = auditRepo.Record(context.Background(), entry, func(ctx context.Context) error {
+		err := postRepo.Delete(ctx, postId)
+		// Until the deletion is committed, readers still see the post, so the
+		// cached pages must not be invalidated yet.
+		assert.Error(t, redisMock.ExpectationsWereMet())
+		return err
+	})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestListAuditEntriesOfTarget(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	auditRepo := repositories.NewAuditRepository(db)
+
+	targetId := uuid.New()
+	cursor := models.Cursor{CreatedAt: time.Now(), Id: uuid.New()}
+	entryId := uuid.New()
+
+	mock.ExpectQuery(`where true and target_id = \$1 and \(createdAt, id\) < \(\$2, \$3\)`).
+		WithArgs(targetId, cursor.CreatedAt, cursor.Id, 21).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor_id", "action", "target_type", "target_id", "details", "createdAt"}).
+			AddRow(entryId, uuid.New(), "users:suspend", "user", targetId, nil, time.Now()))
+
+	page, err := auditRepo.List(context.Background(), targetId, cursor, 20)
+
+	assert.NoError(t, err)
+	assert.Len(t, page.Entries, 1)
+	assert.Equal(t, entryId, page.Entries[0].Id)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
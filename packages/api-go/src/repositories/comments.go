@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/redis/go-redis/v9"
+)
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment models.Comment) (uuid.UUID, error)
+	GetById(ctx context.Context, id uuid.UUID) (models.Comment, error)
+	GetByPost(ctx context.Context, postId, parentId uuid.UUID, cursor models.Cursor, limit int) (models.CommentPage, error)
+	Update(ctx context.Context, id uuid.UUID, content string) error
+	Delete(ctx context.Context, id uuid.UUID) error
+}
+
+type Comments struct {
+	db    *sql.DB
+	redis *redis.Client
+}
+
+func NewCommentRepository(db *sql.DB, redis *redis.Client) *Comments {
+	return &Comments{db, redis}
+}
+
+// commentColumns is the select list read by scanComment. Queries using it must
+// alias comments as c and join the author as u.
+const commentColumns = `c.id, c.post_id, c.parent_id, c.author_id, u.nick, c.content, c.createdAt, c.updatedAt,
+	(select count(*) from comments r where r.parent_id = c.id)`
+
+func scanComment(lines *sql.Rows) (models.Comment, error) {
+	var comment models.Comment
+	var parentId uuid.NullUUID
+	var updatedAt sql.NullTime
+
+	err := lines.Scan(
+		&comment.Id,
+		&comment.PostId,
+		&parentId,
+		&comment.AuthorId,
+		&comment.AuthorNick,
+		&comment.Content,
+		&comment.CreatedAt,
+		&updatedAt,
+		&comment.RepliesCount,
+	)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	if parentId.Valid {
+		comment.ParentId = &parentId.UUID
+	}
+	if updatedAt.Valid {
+		comment.UpdatedAt = &updatedAt.Time
+	}
+
+	return comment, nil
+}
+
+// Create stores a comment and returns its id. The cached posts pages are
+// invalidated because they carry the comments count.
+func (repository Comments) Create(ctx context.Context, comment models.Comment) (uuid.UUID, error) {
+	statement, err := repository.db.PrepareContext(ctx,
+		"insert into comments (post_id, parent_id, author_id, content) values ($1, $2, $3, $4) returning id",
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	defer statement.Close()
+
+	var id uuid.UUID
+	err = statement.QueryRowContext(ctx, comment.PostId, comment.ParentId, comment.AuthorId, comment.Content).Scan(&id)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	invalidatePostPages(repository.redis)
+
+	return id, nil
+}
+
+func (repository Comments) GetById(ctx context.Context, id uuid.UUID) (models.Comment, error) {
+	lines, err := repository.db.QueryContext(ctx, `
+	select `+commentColumns+` from
+	comments c inner join users u
+	on u.id = c.author_id where c.id = $1
+	`, id)
+	if err != nil {
+		return models.Comment{}, err
+	}
+
+	defer lines.Close()
+
+	var comment models.Comment
+	if lines.Next() {
+		comment, err = scanComment(lines)
+		if err != nil {
+			return models.Comment{}, err
+		}
+	}
+
+	return comment, nil
+}
+
+// GetByPost returns a page of comments on postId, oldest first. A uuid.Nil parentId
+// lists the top-level comments, otherwise the direct replies to parentId.
+func (repository Comments) GetByPost(ctx context.Context, postId, parentId uuid.UUID, cursor models.Cursor, limit int) (models.CommentPage, error) {
+	args := []interface{}{postId}
+
+	query := `
+	select ` + commentColumns + `
+	from comments c
+	join users u on u.id = c.author_id
+	where c.post_id = $1`
+
+	if parentId == uuid.Nil {
+		query += " and c.parent_id is null"
+	} else {
+		args = append(args, parentId)
+		query += fmt.Sprintf(" and c.parent_id = $%d", len(args))
+	}
+	if !cursor.IsZero() {
+		args = append(args, cursor.CreatedAt, cursor.Id)
+		query += fmt.Sprintf(" and (c.createdAt, c.id) > ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	query += fmt.Sprintf("\n\torder by c.createdAt, c.id\n\tlimit $%d;", len(args))
+
+	lines, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.CommentPage{}, err
+	}
+
+	defer lines.Close()
+
+	comments := []models.Comment{}
+	for lines.Next() {
+		comment, err := scanComment(lines)
+		if err != nil {
+			return models.CommentPage{}, err
+		}
+		comments = append(comments, comment)
+	}
+
+	page := models.CommentPage{Comments: comments}
+	if len(comments) > limit {
+		page.Comments = comments[:limit]
+		last := page.Comments[limit-1]
+		page.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+	}
+
+	return page, lines.Err()
+}
+
+func (repository Comments) Update(ctx context.Context, id uuid.UUID, content string) error {
+	statement, err := repository.db.PrepareContext(ctx, "update comments set content = $1, updatedAt = CURRENT_TIMESTAMP where id = $2")
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(ctx, content, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes a comment together with its replies.
+func (repository Comments) Delete(ctx context.Context, id uuid.UUID) error {
+	statement, err := conn(ctx, repository.db).PrepareContext(ctx, "delete from comments where id = $1")
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	afterCommit(ctx, func() {
+		invalidatePostPages(repository.redis)
+	})
+
+	return nil
+}
@@ -0,0 +1,165 @@
+package repositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+var commentColumns = []string{"id", "post_id", "parent_id", "author_id", "author_nick", "content", "createdAt", "updatedAt", "replies_count"}
+
+func TestCreateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	parentId := uuid.New()
+	comment := models.Comment{
+		PostId:   uuid.New(),
+		ParentId: &parentId,
+		AuthorId: uuid.New(),
+		Content:  "A reply to the first comment",
+	}
+	commentId := uuid.New()
+
+	mock.ExpectPrepare("insert into comments").
+		ExpectQuery().
+		WithArgs(comment.PostId, comment.ParentId, comment.AuthorId, comment.Content).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(commentId))
+
+	id, err := commentRepo.Create(context.Background(), comment)
+	assert.NoError(t, err)
+	assert.Equal(t, commentId, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCommentById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	commentId := uuid.New()
+	parentId := uuid.New()
+
+	rows := sqlmock.NewRows(commentColumns).
+		AddRow(commentId, uuid.New(), parentId, uuid.New(), "author_nick", "A reply", time.Now(), nil, 0)
+
+	mock.ExpectQuery("select c.id, c.post_id, c.parent_id").
+		WithArgs(commentId).
+		WillReturnRows(rows)
+
+	comment, err := commentRepo.GetById(context.Background(), commentId)
+	assert.NoError(t, err)
+	assert.Equal(t, commentId, comment.Id)
+	assert.Equal(t, parentId, *comment.ParentId)
+	assert.Nil(t, comment.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCommentsByPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	postId := uuid.New()
+	firstId := uuid.New()
+	firstCreatedAt := time.Now()
+
+	rows := sqlmock.NewRows(commentColumns).
+		AddRow(firstId, postId, nil, uuid.New(), "author_nick", "First comment", firstCreatedAt, nil, 3).
+		AddRow(uuid.New(), postId, nil, uuid.New(), "author_nick", "Second comment", firstCreatedAt.Add(time.Minute), nil, 0)
+
+	mock.ExpectQuery(`where c.post_id = \$1 and c.parent_id is null`).
+		WithArgs(postId, 2).
+		WillReturnRows(rows)
+
+	page, err := commentRepo.GetByPost(context.Background(), postId, uuid.Nil, models.Cursor{}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, page.Comments, 1)
+	assert.Nil(t, page.Comments[0].ParentId)
+	assert.Equal(t, uint64(3), page.Comments[0].RepliesCount)
+
+	next, err := models.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, firstId, next.Id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetCommentRepliesWithCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	postId := uuid.New()
+	parentId := uuid.New()
+	cursor := models.Cursor{CreatedAt: time.Now(), Id: uuid.New()}
+
+	mock.ExpectQuery(`and c.parent_id = \$2 and \(c.createdAt, c.id\) > \(\$3, \$4\)`).
+		WithArgs(postId, parentId, cursor.CreatedAt, cursor.Id, 21).
+		WillReturnRows(sqlmock.NewRows(commentColumns))
+
+	page, err := commentRepo.GetByPost(context.Background(), postId, parentId, cursor, 20)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Comments)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	commentId := uuid.New()
+
+	mock.ExpectPrepare("update comments set content =").
+		ExpectExec().
+		WithArgs("Edited comment", commentId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = commentRepo.Update(context.Background(), commentId, "Edited comment")
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteComment(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	commentRepo := repositories.NewCommentRepository(db, redis)
+
+	commentId := uuid.New()
+
+	mock.ExpectPrepare("delete from comments where id").
+		ExpectExec().
+		WithArgs(commentId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = commentRepo.Delete(context.Background(), commentId)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
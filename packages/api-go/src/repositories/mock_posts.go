@@ -5,6 +5,7 @@
 package repositories
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,101 +37,148 @@ func (m *MockPostRepository) EXPECT() *MockPostRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockPostRepository) Create(userId uuid.UUID, post models.Post) error {
+func (m *MockPostRepository) Create(ctx context.Context, userId uuid.UUID, post models.Post) (models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", userId, post)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "Create", ctx, userId, post)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockPostRepositoryMockRecorder) Create(userId, post interface{}) *gomock.Call {
+func (mr *MockPostRepositoryMockRecorder) Create(ctx, userId, post interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPostRepository)(nil).Create), userId, post)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockPostRepository)(nil).Create), ctx, userId, post)
 }
 
 // Delete mocks base method.
-func (m *MockPostRepository) Delete(id uuid.UUID) error {
+func (m *MockPostRepository) Delete(ctx context.Context, id uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", id)
+	ret := m.ctrl.Call(m, "Delete", ctx, id)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockPostRepositoryMockRecorder) Delete(id interface{}) *gomock.Call {
+func (mr *MockPostRepositoryMockRecorder) Delete(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPostRepository)(nil).Delete), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockPostRepository)(nil).Delete), ctx, id)
 }
 
-// Dislike mocks base method.
-func (m *MockPostRepository) Dislike(id uuid.UUID) error {
+// GetByTag mocks base method.
+func (m *MockPostRepository) GetByTag(ctx context.Context, userId uuid.UUID, tag string, cursor models.Cursor, limit int) (models.PostPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Dislike", id)
-	ret0, _ := ret[0].(error)
-	return ret0
+	ret := m.ctrl.Call(m, "GetByTag", ctx, userId, tag, cursor, limit)
+	ret0, _ := ret[0].(models.PostPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
 }
 
-// Dislike indicates an expected call of Dislike.
-func (mr *MockPostRepositoryMockRecorder) Dislike(id interface{}) *gomock.Call {
+// GetByTag indicates an expected call of GetByTag.
+func (mr *MockPostRepositoryMockRecorder) GetByTag(ctx, userId, tag, cursor, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Dislike", reflect.TypeOf((*MockPostRepository)(nil).Dislike), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetByTag", reflect.TypeOf((*MockPostRepository)(nil).GetByTag), ctx, userId, tag, cursor, limit)
 }
 
 // GetPostById mocks base method.
-func (m *MockPostRepository) GetPostById(id uuid.UUID) (models.Post, error) {
+func (m *MockPostRepository) GetPostById(ctx context.Context, id uuid.UUID) (models.Post, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPostById", id)
+	ret := m.ctrl.Call(m, "GetPostById", ctx, id)
 	ret0, _ := ret[0].(models.Post)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPostById indicates an expected call of GetPostById.
-func (mr *MockPostRepositoryMockRecorder) GetPostById(id interface{}) *gomock.Call {
+func (mr *MockPostRepositoryMockRecorder) GetPostById(ctx, id interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostById", reflect.TypeOf((*MockPostRepository)(nil).GetPostById), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPostById", reflect.TypeOf((*MockPostRepository)(nil).GetPostById), ctx, id)
 }
 
 // GetPosts mocks base method.
-func (m *MockPostRepository) GetPosts() ([]models.Post, error) {
+func (m *MockPostRepository) GetPosts(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetPosts")
-	ret0, _ := ret[0].([]models.Post)
+	ret := m.ctrl.Call(m, "GetPosts", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].(models.PostPage)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetPosts indicates an expected call of GetPosts.
-func (mr *MockPostRepositoryMockRecorder) GetPosts() *gomock.Call {
+func (mr *MockPostRepositoryMockRecorder) GetPosts(ctx, userId, cursor, limit interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostRepository)(nil).GetPosts))
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPosts", reflect.TypeOf((*MockPostRepository)(nil).GetPosts), ctx, userId, cursor, limit)
 }
 
-// Like mocks base method.
-func (m *MockPostRepository) Like(id uuid.UUID) error {
+// GetTimeline mocks base method.
+func (m *MockPostRepository) GetTimeline(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Like", id)
+	ret := m.ctrl.Call(m, "GetTimeline", ctx, userId, cursor, limit)
+	ret0, _ := ret[0].(models.PostPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTimeline indicates an expected call of GetTimeline.
+func (mr *MockPostRepositoryMockRecorder) GetTimeline(ctx, userId, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTimeline", reflect.TypeOf((*MockPostRepository)(nil).GetTimeline), ctx, userId, cursor, limit)
+}
+
+// React mocks base method.
+func (m *MockPostRepository) React(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "React", ctx, userId, postId, reaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
-// Like indicates an expected call of Like.
-func (mr *MockPostRepositoryMockRecorder) Like(id interface{}) *gomock.Call {
+// React indicates an expected call of React.
+func (mr *MockPostRepositoryMockRecorder) React(ctx, userId, postId, reaction interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Like", reflect.TypeOf((*MockPostRepository)(nil).Like), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "React", reflect.TypeOf((*MockPostRepository)(nil).React), ctx, userId, postId, reaction)
 }
 
-// Update mocks base method.
-func (m *MockPostRepository) Update(id uuid.UUID, post models.Post) error {
+// RemoveReaction mocks base method.
+func (m *MockPostRepository) RemoveReaction(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", id, post)
+	ret := m.ctrl.Call(m, "RemoveReaction", ctx, userId, postId, reaction)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
+// RemoveReaction indicates an expected call of RemoveReaction.
+func (mr *MockPostRepositoryMockRecorder) RemoveReaction(ctx, userId, postId, reaction interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveReaction", reflect.TypeOf((*MockPostRepository)(nil).RemoveReaction), ctx, userId, postId, reaction)
+}
+
+// Search mocks base method.
+func (m *MockPostRepository) Search(ctx context.Context, userId uuid.UUID, query string, limit, offset int) ([]models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, userId, query, limit, offset)
+	ret0, _ := ret[0].([]models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockPostRepositoryMockRecorder) Search(ctx, userId, query, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockPostRepository)(nil).Search), ctx, userId, query, limit, offset)
+}
+
+// Update mocks base method.
+func (m *MockPostRepository) Update(ctx context.Context, id uuid.UUID, post models.Post) (models.Post, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Update", ctx, id, post)
+	ret0, _ := ret[0].(models.Post)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
 // Update indicates an expected call of Update.
-func (mr *MockPostRepositoryMockRecorder) Update(id, post interface{}) *gomock.Call {
+func (mr *MockPostRepositoryMockRecorder) Update(ctx, id, post interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPostRepository)(nil).Update), id, post)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockPostRepository)(nil).Update), ctx, id, post)
 }
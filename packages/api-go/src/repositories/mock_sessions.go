@@ -0,0 +1,123 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: sessions.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockSessionRepository is a mock of SessionRepository interface.
+type MockSessionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockSessionRepositoryMockRecorder
+}
+
+// MockSessionRepositoryMockRecorder is the mock recorder for MockSessionRepository.
+type MockSessionRepositoryMockRecorder struct {
+	mock *MockSessionRepository
+}
+
+// NewMockSessionRepository creates a new mock instance.
+func NewMockSessionRepository(ctrl *gomock.Controller) *MockSessionRepository {
+	mock := &MockSessionRepository{ctrl: ctrl}
+	mock.recorder = &MockSessionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockSessionRepository) EXPECT() *MockSessionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateRefreshToken mocks base method.
+func (m *MockSessionRepository) CreateRefreshToken(ctx context.Context, userId, familyId uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateRefreshToken", ctx, userId, familyId, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateRefreshToken indicates an expected call of CreateRefreshToken.
+func (mr *MockSessionRepositoryMockRecorder) CreateRefreshToken(ctx, userId, familyId, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateRefreshToken", reflect.TypeOf((*MockSessionRepository)(nil).CreateRefreshToken), ctx, userId, familyId, tokenHash, expiresAt)
+}
+
+// DenyAccessToken mocks base method.
+func (m *MockSessionRepository) DenyAccessToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DenyAccessToken", ctx, tokenId, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DenyAccessToken indicates an expected call of DenyAccessToken.
+func (mr *MockSessionRepositoryMockRecorder) DenyAccessToken(ctx, tokenId, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DenyAccessToken", reflect.TypeOf((*MockSessionRepository)(nil).DenyAccessToken), ctx, tokenId, expiresAt)
+}
+
+// IsRevoked mocks base method.
+func (m *MockSessionRepository) IsRevoked(ctx context.Context, userId uuid.UUID, tokenId string, issuedAt time.Time) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "IsRevoked", ctx, userId, tokenId, issuedAt)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// IsRevoked indicates an expected call of IsRevoked.
+func (mr *MockSessionRepositoryMockRecorder) IsRevoked(ctx, userId, tokenId, issuedAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "IsRevoked", reflect.TypeOf((*MockSessionRepository)(nil).IsRevoked), ctx, userId, tokenId, issuedAt)
+}
+
+// RevokeRefreshToken mocks base method.
+func (m *MockSessionRepository) RevokeRefreshToken(ctx context.Context, userId uuid.UUID, tokenHash string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeRefreshToken", ctx, userId, tokenHash)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeRefreshToken indicates an expected call of RevokeRefreshToken.
+func (mr *MockSessionRepositoryMockRecorder) RevokeRefreshToken(ctx, userId, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeRefreshToken", reflect.TypeOf((*MockSessionRepository)(nil).RevokeRefreshToken), ctx, userId, tokenHash)
+}
+
+// RevokeUser mocks base method.
+func (m *MockSessionRepository) RevokeUser(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RevokeUser", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RevokeUser indicates an expected call of RevokeUser.
+func (mr *MockSessionRepositoryMockRecorder) RevokeUser(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RevokeUser", reflect.TypeOf((*MockSessionRepository)(nil).RevokeUser), ctx, userId)
+}
+
+// RotateRefreshToken mocks base method.
+func (m *MockSessionRepository) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (uuid.UUID, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RotateRefreshToken", ctx, tokenHash, newTokenHash, expiresAt)
+	ret0, _ := ret[0].(uuid.UUID)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RotateRefreshToken indicates an expected call of RotateRefreshToken.
+func (mr *MockSessionRepositoryMockRecorder) RotateRefreshToken(ctx, tokenHash, newTokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RotateRefreshToken", reflect.TypeOf((*MockSessionRepository)(nil).RotateRefreshToken), ctx, tokenHash, newTokenHash, expiresAt)
+}
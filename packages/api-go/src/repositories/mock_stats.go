@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/repositories/stats.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	context "context"
+	reflect "reflect"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/otaviopontes/api-go/src/models"
+)
+
+// MockStatsRepository is a mock of StatsRepository interface.
+type MockStatsRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockStatsRepositoryMockRecorder
+}
+
+// MockStatsRepositoryMockRecorder is the mock recorder for MockStatsRepository.
+type MockStatsRepositoryMockRecorder struct {
+	mock *MockStatsRepository
+}
+
+// NewMockStatsRepository creates a new mock instance.
+func NewMockStatsRepository(ctrl *gomock.Controller) *MockStatsRepository {
+	mock := &MockStatsRepository{ctrl: ctrl}
+	mock.recorder = &MockStatsRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStatsRepository) EXPECT() *MockStatsRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Get mocks base method.
+func (m *MockStatsRepository) Get(ctx context.Context) (models.Stats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Get", ctx)
+	ret0, _ := ret[0].(models.Stats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Get indicates an expected call of Get.
+func (mr *MockStatsRepositoryMockRecorder) Get(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockStatsRepository)(nil).Get), ctx)
+}
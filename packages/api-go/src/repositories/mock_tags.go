@@ -0,0 +1,66 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: src/repositories/tags.go
+
+// Package repositories is a generated GoMock package.
+package repositories
+
+import (
+	context "context"
+	reflect "reflect"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	models "github.com/otaviopontes/api-go/src/models"
+)
+
+// MockTagRepository is a mock of TagRepository interface.
+type MockTagRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockTagRepositoryMockRecorder
+}
+
+// MockTagRepositoryMockRecorder is the mock recorder for MockTagRepository.
+type MockTagRepositoryMockRecorder struct {
+	mock *MockTagRepository
+}
+
+// NewMockTagRepository creates a new mock instance.
+func NewMockTagRepository(ctrl *gomock.Controller) *MockTagRepository {
+	mock := &MockTagRepository{ctrl: ctrl}
+	mock.recorder = &MockTagRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockTagRepository) EXPECT() *MockTagRepositoryMockRecorder {
+	return m.recorder
+}
+
+// Record mocks base method.
+func (m *MockTagRepository) Record(ctx context.Context, tags []string, at time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Record", ctx, tags, at)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Record indicates an expected call of Record.
+func (mr *MockTagRepositoryMockRecorder) Record(ctx, tags, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Record", reflect.TypeOf((*MockTagRepository)(nil).Record), ctx, tags, at)
+}
+
+// Trending mocks base method.
+func (m *MockTagRepository) Trending(ctx context.Context, window time.Duration, limit int) ([]models.TrendingTag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Trending", ctx, window, limit)
+	ret0, _ := ret[0].([]models.TrendingTag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Trending indicates an expected call of Trending.
+func (mr *MockTagRepositoryMockRecorder) Trending(ctx, window, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Trending", reflect.TypeOf((*MockTagRepository)(nil).Trending), ctx, window, limit)
+}
@@ -5,6 +5,7 @@
 package repositories
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -36,117 +37,218 @@ func (m *MockUserRepository) EXPECT() *MockUserRepositoryMockRecorder {
 }
 
 // Create mocks base method.
-func (m *MockUserRepository) Create(user models.User) error {
+func (m *MockUserRepository) Create(ctx context.Context, user models.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", user)
+	ret := m.ctrl.Call(m, "Create", ctx, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockUserRepositoryMockRecorder) Create(user interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Create(ctx, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockUserRepository)(nil).Create), ctx, user)
 }
 
 // Delete mocks base method.
-func (m *MockUserRepository) Delete(userId uuid.UUID) error {
+func (m *MockUserRepository) Delete(ctx context.Context, userId uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Delete", userId)
+	ret := m.ctrl.Call(m, "Delete", ctx, userId)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Delete indicates an expected call of Delete.
-func (mr *MockUserRepositoryMockRecorder) Delete(userId interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Delete(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockUserRepository)(nil).Delete), ctx, userId)
 }
 
-// Get mocks base method.
-func (m *MockUserRepository) Get(nameOrNick string) ([]models.User, error) {
+// Follow mocks base method.
+func (m *MockUserRepository) Follow(ctx context.Context, followerId, followedId uuid.UUID) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Get", nameOrNick)
-	ret0, _ := ret[0].([]models.User)
-	ret1, _ := ret[1].(error)
-	return ret0, ret1
+	ret := m.ctrl.Call(m, "Follow", ctx, followerId, followedId)
+	ret0, _ := ret[0].(error)
+	return ret0
 }
 
-// Get indicates an expected call of Get.
-func (mr *MockUserRepositoryMockRecorder) Get(nameOrNick interface{}) *gomock.Call {
+// Follow indicates an expected call of Follow.
+func (mr *MockUserRepositoryMockRecorder) Follow(ctx, followerId, followedId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockUserRepository)(nil).Get), nameOrNick)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Follow", reflect.TypeOf((*MockUserRepository)(nil).Follow), ctx, followerId, followedId)
 }
 
 // GetById mocks base method.
-func (m *MockUserRepository) GetById(userId uuid.UUID) (models.User, error) {
+func (m *MockUserRepository) GetById(ctx context.Context, userId uuid.UUID) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetById", userId)
+	ret := m.ctrl.Call(m, "GetById", ctx, userId)
 	ret0, _ := ret[0].(models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetById indicates an expected call of GetById.
-func (mr *MockUserRepositoryMockRecorder) GetById(userId interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) GetById(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockUserRepository)(nil).GetById), ctx, userId)
+}
+
+// GetFollowers mocks base method.
+func (m *MockUserRepository) GetFollowers(ctx context.Context, userId uuid.UUID) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowers", ctx, userId)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowers indicates an expected call of GetFollowers.
+func (mr *MockUserRepositoryMockRecorder) GetFollowers(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowers", reflect.TypeOf((*MockUserRepository)(nil).GetFollowers), ctx, userId)
+}
+
+// GetFollowing mocks base method.
+func (m *MockUserRepository) GetFollowing(ctx context.Context, userId uuid.UUID) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetFollowing", ctx, userId)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFollowing indicates an expected call of GetFollowing.
+func (mr *MockUserRepositoryMockRecorder) GetFollowing(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFollowing", reflect.TypeOf((*MockUserRepository)(nil).GetFollowing), ctx, userId)
+}
+
+// List mocks base method.
+func (m *MockUserRepository) List(ctx context.Context, filter models.UserFilter, cursor models.Cursor, limit int) (models.UserPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "List", ctx, filter, cursor, limit)
+	ret0, _ := ret[0].(models.UserPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// List indicates an expected call of List.
+func (mr *MockUserRepositoryMockRecorder) List(ctx, filter, cursor, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "List", reflect.TypeOf((*MockUserRepository)(nil).List), ctx, filter, cursor, limit)
+}
+
+// Search mocks base method.
+func (m *MockUserRepository) Search(ctx context.Context, query string, limit, offset int) ([]models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Search", ctx, query, limit, offset)
+	ret0, _ := ret[0].([]models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Search indicates an expected call of Search.
+func (mr *MockUserRepositoryMockRecorder) Search(ctx, query, limit, offset interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetById", reflect.TypeOf((*MockUserRepository)(nil).GetById), userId)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Search", reflect.TypeOf((*MockUserRepository)(nil).Search), ctx, query, limit, offset)
 }
 
 // SearchByEmail mocks base method.
-func (m *MockUserRepository) SearchByEmail(email string) (models.User, error) {
+func (m *MockUserRepository) SearchByEmail(ctx context.Context, email string) (models.User, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchByEmail", email)
+	ret := m.ctrl.Call(m, "SearchByEmail", ctx, email)
 	ret0, _ := ret[0].(models.User)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchByEmail indicates an expected call of SearchByEmail.
-func (mr *MockUserRepositoryMockRecorder) SearchByEmail(email interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) SearchByEmail(ctx, email interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByEmail", reflect.TypeOf((*MockUserRepository)(nil).SearchByEmail), email)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchByEmail", reflect.TypeOf((*MockUserRepository)(nil).SearchByEmail), ctx, email)
 }
 
 // SearchPassword mocks base method.
-func (m *MockUserRepository) SearchPassword(id uuid.UUID) (string, error) {
+func (m *MockUserRepository) SearchPassword(ctx context.Context, id uuid.UUID) (string, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "SearchPassword", id)
+	ret := m.ctrl.Call(m, "SearchPassword", ctx, id)
 	ret0, _ := ret[0].(string)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // SearchPassword indicates an expected call of SearchPassword.
-func (mr *MockUserRepositoryMockRecorder) SearchPassword(id interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) SearchPassword(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPassword", reflect.TypeOf((*MockUserRepository)(nil).SearchPassword), ctx, id)
+}
+
+// Suspend mocks base method.
+func (m *MockUserRepository) Suspend(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Suspend", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Suspend indicates an expected call of Suspend.
+func (mr *MockUserRepositoryMockRecorder) Suspend(ctx, userId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Suspend", reflect.TypeOf((*MockUserRepository)(nil).Suspend), ctx, userId)
+}
+
+// Unfollow mocks base method.
+func (m *MockUserRepository) Unfollow(ctx context.Context, followerId, followedId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unfollow", ctx, followerId, followedId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unfollow indicates an expected call of Unfollow.
+func (mr *MockUserRepositoryMockRecorder) Unfollow(ctx, followerId, followedId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unfollow", reflect.TypeOf((*MockUserRepository)(nil).Unfollow), ctx, followerId, followedId)
+}
+
+// Unsuspend mocks base method.
+func (m *MockUserRepository) Unsuspend(ctx context.Context, userId uuid.UUID) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Unsuspend", ctx, userId)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Unsuspend indicates an expected call of Unsuspend.
+func (mr *MockUserRepositoryMockRecorder) Unsuspend(ctx, userId interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchPassword", reflect.TypeOf((*MockUserRepository)(nil).SearchPassword), id)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Unsuspend", reflect.TypeOf((*MockUserRepository)(nil).Unsuspend), ctx, userId)
 }
 
 // Update mocks base method.
-func (m *MockUserRepository) Update(userId uuid.UUID, user models.User) error {
+func (m *MockUserRepository) Update(ctx context.Context, userId uuid.UUID, user models.User) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Update", userId, user)
+	ret := m.ctrl.Call(m, "Update", ctx, userId, user)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // Update indicates an expected call of Update.
-func (mr *MockUserRepositoryMockRecorder) Update(userId, user interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) Update(ctx, userId, user interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), userId, user)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Update", reflect.TypeOf((*MockUserRepository)(nil).Update), ctx, userId, user)
 }
 
 // UpdatePassword mocks base method.
-func (m *MockUserRepository) UpdatePassword(userId uuid.UUID, password []byte) error {
+func (m *MockUserRepository) UpdatePassword(ctx context.Context, userId uuid.UUID, password []byte) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdatePassword", userId, password)
+	ret := m.ctrl.Call(m, "UpdatePassword", ctx, userId, password)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // UpdatePassword indicates an expected call of UpdatePassword.
-func (mr *MockUserRepositoryMockRecorder) UpdatePassword(userId, password interface{}) *gomock.Call {
+func (mr *MockUserRepositoryMockRecorder) UpdatePassword(ctx, userId, password interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), userId, password)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdatePassword", reflect.TypeOf((*MockUserRepository)(nil).UpdatePassword), ctx, userId, password)
 }
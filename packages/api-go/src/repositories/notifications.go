@@ -0,0 +1,242 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/redis/go-redis/v9"
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notifications ...models.Notification) error
+	GetByUser(ctx context.Context, userId uuid.UUID, unreadOnly bool, cursor models.Cursor, limit int) (models.NotificationPage, error)
+	CountUnread(ctx context.Context, userId uuid.UUID) (uint64, error)
+	MarkRead(ctx context.Context, userId uuid.UUID, ids []uuid.UUID) error
+}
+
+// unreadTTL bounds how long a cached unread counter may drift from the table.
+const unreadTTL = time.Hour
+
+// adjustUnreadScript moves a cached unread counter by ARGV[1] only when the key
+// is warm, so a cold counter is always recomputed from the table, and never
+// lets it drop below zero.
+var adjustUnreadScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	if redis.call('INCRBY', KEYS[1], ARGV[1]) < 0 then
+		redis.call('SET', KEYS[1], 0, 'KEEPTTL')
+	end
+end
+return 0
+`)
+
+// UnreadKey returns the Redis key caching the unread counter of userId.
+func UnreadKey(userId uuid.UUID) string {
+	return fmt.Sprintf("notifications:unread:%s", userId)
+}
+
+type Notifications struct {
+	db    *sql.DB
+	redis *redis.Client
+}
+
+func NewNotificationRepository(db *sql.DB, redis *redis.Client) *Notifications {
+	return &Notifications{db, redis}
+}
+
+// Create records notifications, skipping the ones addressed to their own actor.
+// A reaction, mention or follow that was already notified is not notified
+// again, so toggling a like does not flood the recipient.
+func (repository Notifications) Create(ctx context.Context, notifications ...models.Notification) error {
+	statement, err := repository.db.PrepareContext(ctx, `
+	insert into notifications (user_id, type, actor_id, post_id, comment_id, reaction)
+	values ($1, $2, $3, $4, $5, nullif($6, ''))
+	on conflict do nothing`)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	for _, notification := range notifications {
+		if notification.UserId == notification.ActorId {
+			continue
+		}
+
+		result, err := statement.ExecContext(ctx,
+			notification.UserId,
+			notification.Type,
+			notification.ActorId,
+			notification.PostId,
+			notification.CommentId,
+			notification.Reaction,
+		)
+		if err != nil {
+			return err
+		}
+
+		added, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if added > 0 {
+			repository.adjustUnread(notification.UserId, added)
+		}
+	}
+
+	return nil
+}
+
+// GetByUser returns a page of the notifications of userId older than cursor,
+// newest first, along with the unread counter.
+func (repository Notifications) GetByUser(ctx context.Context, userId uuid.UUID, unreadOnly bool, cursor models.Cursor, limit int) (models.NotificationPage, error) {
+	args := []interface{}{userId}
+	query := `
+	select n.id, n.user_id, n.type, n.actor_id, coalesce(a.nick, ''), n.post_id, n.comment_id,
+	coalesce(n.reaction, ''), n.readAt is not null, n.createdAt
+	from notifications n
+	left join users a on a.id = n.actor_id
+	where n.user_id = $1`
+	if unreadOnly {
+		query += " and n.readAt is null"
+	}
+	if !cursor.IsZero() {
+		args = append(args, cursor.CreatedAt, cursor.Id)
+		query += fmt.Sprintf(" and (n.createdAt, n.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	query += fmt.Sprintf("\n\torder by n.createdAt desc, n.id desc\n\tlimit $%d", len(args))
+
+	lines, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.NotificationPage{}, err
+	}
+
+	defer lines.Close()
+
+	notifications := []models.Notification{}
+	for lines.Next() {
+		var notification models.Notification
+		var actorId, postId, commentId uuid.NullUUID
+
+		err := lines.Scan(
+			&notification.Id,
+			&notification.UserId,
+			&notification.Type,
+			&actorId,
+			&notification.ActorNick,
+			&postId,
+			&commentId,
+			&notification.Reaction,
+			&notification.Read,
+			&notification.CreatedAt,
+		)
+		if err != nil {
+			return models.NotificationPage{}, err
+		}
+
+		notification.ActorId = actorId.UUID
+		if postId.Valid {
+			notification.PostId = &postId.UUID
+		}
+		if commentId.Valid {
+			notification.CommentId = &commentId.UUID
+		}
+		notifications = append(notifications, notification)
+	}
+
+	if err := lines.Err(); err != nil {
+		return models.NotificationPage{}, err
+	}
+
+	page := models.NotificationPage{Notifications: notifications}
+	if len(notifications) > limit {
+		page.Notifications = notifications[:limit]
+		last := page.Notifications[limit-1]
+		page.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+	}
+
+	page.Unread, err = repository.CountUnread(ctx, userId)
+	if err != nil {
+		return models.NotificationPage{}, err
+	}
+
+	return page, nil
+}
+
+// CountUnread returns the number of unread notifications of userId, cached in
+// Redis and recomputed from the table when the cache is cold.
+func (repository Notifications) CountUnread(ctx context.Context, userId uuid.UUID) (uint64, error) {
+	key := UnreadKey(userId)
+
+	cached, err := repository.redis.Get(ctx, key).Result()
+	if err == nil {
+		if unread, err := strconv.ParseUint(cached, 10, 64); err == nil {
+			return unread, nil
+		}
+	}
+
+	var unread uint64
+	err = repository.db.QueryRowContext(ctx,
+		"select count(*) from notifications where user_id = $1 and readAt is null", userId,
+	).Scan(&unread)
+	if err != nil {
+		return 0, err
+	}
+
+	repository.redis.Set(ctx, key, unread, unreadTTL)
+
+	return unread, nil
+}
+
+// MarkRead marks the given notifications of userId as read, or all of them
+// when ids is empty.
+func (repository Notifications) MarkRead(ctx context.Context, userId uuid.UUID, ids []uuid.UUID) error {
+	query := "update notifications set readAt = CURRENT_TIMESTAMP where user_id = $1 and readAt is null"
+	args := []interface{}{userId}
+	if len(ids) > 0 {
+		query += " and id = any($2::uuid[])"
+		args = append(args, pq.Array(uuidStrings(ids)))
+	}
+
+	statement, err := repository.db.PrepareContext(ctx, query)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	result, err := statement.ExecContext(ctx, args...)
+	if err != nil {
+		return err
+	}
+
+	read, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if read > 0 {
+		repository.adjustUnread(userId, -read)
+	}
+
+	return nil
+}
+
+// adjustUnread runs outside the request context: once the table changed, the
+// counter must follow even when the request is canceled.
+func (repository Notifications) adjustUnread(userId uuid.UUID, delta int64) {
+	adjustUnreadScript.Run(context.Background(), repository.redis, []string{UnreadKey(userId)}, delta)
+}
+
+func uuidStrings(ids []uuid.UUID) []string {
+	strings := make([]string, len(ids))
+	for i, id := range ids {
+		strings[i] = id.String()
+	}
+	return strings
+}
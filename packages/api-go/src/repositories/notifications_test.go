@@ -0,0 +1,159 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+// ignoreScriptSha matches an EVALSHA by its keys and arguments, leaving out the
+// hash of the unexported script.
+func ignoreScriptSha(expected, actual []interface{}) error {
+	if !reflect.DeepEqual(expected[2:], actual[2:]) {
+		return fmt.Errorf("expected %v, got %v", expected, actual)
+	}
+	return nil
+}
+
+func TestCreateNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	notificationRepo := repositories.NewNotificationRepository(db, redis)
+
+	authorId := uuid.New()
+	actorId := uuid.New()
+	postId := uuid.New()
+
+	prepared := mock.ExpectPrepare("insert into notifications")
+	prepared.ExpectExec().
+		WithArgs(authorId, models.NotificationReaction, actorId, &postId, nil, models.ReactionLove).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	redisMock.CustomMatch(ignoreScriptSha).
+		ExpectEvalSha("", []string{repositories.UnreadKey(authorId)}, int64(1)).SetVal(int64(0))
+
+	err = notificationRepo.Create(context.Background(),
+		models.Notification{UserId: authorId, Type: models.NotificationReaction, ActorId: actorId, PostId: &postId, Reaction: models.ReactionLove},
+		models.Notification{UserId: actorId, Type: models.NotificationReaction, ActorId: actorId, PostId: &postId, Reaction: models.ReactionLove},
+	)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestCreateDuplicateNotification(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	notificationRepo := repositories.NewNotificationRepository(db, redis)
+
+	userId := uuid.New()
+	followerId := uuid.New()
+
+	mock.ExpectPrepare("on conflict do nothing").
+		ExpectExec().
+		WithArgs(userId, models.NotificationFollow, followerId, nil, nil, models.Reaction("")).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = notificationRepo.Create(context.Background(), models.Notification{UserId: userId, Type: models.NotificationFollow, ActorId: followerId})
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetUnreadNotifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	notificationRepo := repositories.NewNotificationRepository(db, redis)
+
+	userId := uuid.New()
+	postId := uuid.New()
+	createdAt := time.Now()
+
+	rows := sqlmock.NewRows([]string{"id", "user_id", "type", "actor_id", "actor_nick", "post_id", "comment_id", "reaction", "read", "createdAt"}).
+		AddRow(uuid.New(), userId, "mention", uuid.New(), "alice", postId, nil, "", false, createdAt).
+		AddRow(uuid.New(), userId, "follow", uuid.New(), "bob", nil, nil, "", false, createdAt.Add(-time.Minute))
+
+	mock.ExpectQuery(`where n.user_id = \$1 and n.readAt is null`).
+		WithArgs(userId, 2).
+		WillReturnRows(rows)
+
+	redisMock.ExpectGet(repositories.UnreadKey(userId)).SetVal("7")
+
+	page, err := notificationRepo.GetByUser(context.Background(), userId, true, models.Cursor{}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, page.Notifications, 1)
+	assert.Equal(t, models.NotificationMention, page.Notifications[0].Type)
+	assert.Equal(t, &postId, page.Notifications[0].PostId)
+	assert.Nil(t, page.Notifications[0].CommentId)
+	assert.Equal(t, uint64(7), page.Unread)
+	assert.NotEmpty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestCountUnreadWhenCacheIsCold(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	notificationRepo := repositories.NewNotificationRepository(db, redis)
+
+	userId := uuid.New()
+	key := repositories.UnreadKey(userId)
+
+	redisMock.ExpectGet(key).RedisNil()
+	mock.ExpectQuery(`select count\(\*\) from notifications where user_id = \$1 and readAt is null`).
+		WithArgs(userId).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(3))
+	redisMock.ExpectSet(key, uint64(3), time.Hour).SetVal("OK")
+
+	unread, err := notificationRepo.CountUnread(context.Background(), userId)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(3), unread)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestMarkNotificationsRead(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	notificationRepo := repositories.NewNotificationRepository(db, redis)
+
+	userId := uuid.New()
+	ids := []uuid.UUID{uuid.New(), uuid.New()}
+
+	mock.ExpectPrepare(`update notifications set readAt = CURRENT_TIMESTAMP where user_id = \$1 and readAt is null and id = any\(\$2::uuid\[\]\)`).
+		ExpectExec().
+		WithArgs(userId, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	redisMock.CustomMatch(ignoreScriptSha).
+		ExpectEvalSha("", []string{repositories.UnreadKey(userId)}, int64(-2)).SetVal(int64(0))
+
+	err = notificationRepo.MarkRead(context.Background(), userId, ids)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
@@ -4,23 +4,110 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/otaviopontes/api-go/src/metrics"
 	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/timelines"
 	"github.com/redis/go-redis/v9"
 )
 
+var ErrPostNotFound = errors.New("post not found with this id")
+
 type PostRepository interface {
-	Create(userId uuid.UUID, post models.Post) error
-	GetPostById(id uuid.UUID) (models.Post, error)
-	GetPosts() ([]models.Post, error)
-	Update(id uuid.UUID, post models.Post) error
-	Delete(id uuid.UUID) error
-	Like(id uuid.UUID) error
-	Dislike(id uuid.UUID) error
+	Create(ctx context.Context, userId uuid.UUID, post models.Post) (models.Post, error)
+	GetPostById(ctx context.Context, id uuid.UUID) (models.Post, error)
+	GetPosts(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error)
+	GetTimeline(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error)
+	GetByTag(ctx context.Context, userId uuid.UUID, tag string, cursor models.Cursor, limit int) (models.PostPage, error)
+	Search(ctx context.Context, userId uuid.UUID, query string, limit, offset int) ([]models.Post, error)
+	Update(ctx context.Context, id uuid.UUID, post models.Post) (models.Post, error)
+	Delete(ctx context.Context, id uuid.UUID) error
+	React(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error
+	RemoveReaction(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error
+}
+
+// postColumns is the select list read by scanPost. Queries using it must alias
+// posts as p and join the author as u.
+const postColumns = `p.id, p.title, p.content, p.author_id, p.createdat, u.nick,
+	(select count(*) from comments c where c.post_id = p.id),
+	(select coalesce(json_agg(json_build_object(
+		'userId', m.user_id, 'nick', mu.nick, 'offset', m.position, 'length', m.length
+	) order by m.position), '[]')
+	from post_mentions m join users mu on mu.id = m.user_id where m.post_id = p.id)`
+
+func scanPost(lines *sql.Rows) (models.Post, error) {
+	var post models.Post
+	var mentions []byte
+	err := lines.Scan(
+		&post.Id,
+		&post.Title,
+		&post.Content,
+		&post.AuthorId,
+		&post.CreatedAt,
+		&post.AuthorNick,
+		&post.CommentsCount,
+		&mentions,
+	)
+	if err != nil {
+		return post, err
+	}
+	post.Tags = models.ExtractTags(post.Content)
+
+	return post, json.Unmarshal(mentions, &post.Mentions)
+}
+
+// resolvedMentions aggregates the user_id and position columns of the
+// mentioned CTE shared by Create and Update for resolveMentions.
+const resolvedMentions = `(select coalesce(json_agg(json_build_object('userId', user_id, 'offset', position)), '[]') from mentioned)`
+
+// resolveMentions keeps the mentions whose offset appears in resolved, the JSON
+// built by resolvedMentions, and sets their user id.
+func resolveMentions(mentions []models.Mention, resolved []byte) ([]models.Mention, error) {
+	var users []models.Mention
+	if err := json.Unmarshal(resolved, &users); err != nil {
+		return nil, err
+	}
+
+	userIds := make(map[int]uuid.UUID, len(users))
+	for _, user := range users {
+		userIds[user.Offset] = user.UserId
+	}
+
+	result := []models.Mention{}
+	for _, mention := range mentions {
+		if userId, ok := userIds[mention.Offset]; ok {
+			mention.UserId = userId
+			result = append(result, mention)
+		}
+	}
+
+	return result, nil
 }
 
+// mentionArrays splits mentions into the parallel nick, position and length
+// arrays unnested by Create and Update.
+func mentionArrays(mentions []models.Mention) (interface{}, interface{}, interface{}) {
+	nicks := make([]string, len(mentions))
+	positions := make([]int64, len(mentions))
+	lengths := make([]int64, len(mentions))
+	for i, mention := range mentions {
+		nicks[i] = mention.Nick
+		positions[i] = int64(mention.Offset)
+		lengths[i] = int64(mention.Length)
+	}
+	return pq.Array(nicks), pq.Array(positions), pq.Array(lengths)
+}
+
+// Posts runs its queries under the request context. The cache invalidations and
+// fan-out following a committed write use their own context instead, so they
+// still happen when the request is canceled right after the write.
 type Posts struct {
 	db    *sql.DB
 	redis *redis.Client
@@ -30,169 +117,539 @@ func NewPostRepository(db *sql.DB, redis *redis.Client) *Posts {
 	return &Posts{db, redis}
 }
 
-func (repository Posts) Create(userId uuid.UUID, post models.Post) error {
-	statement, err := repository.db.Prepare("INSERT INTO posts (title, content, author_id) VALUES ($1, $2, $3);")
+// Create inserts the post along with its tags and the mentions whose nick
+// belongs to a user, and returns it with its id and resolved mentions.
+func (repository Posts) Create(ctx context.Context, userId uuid.UUID, post models.Post) (models.Post, error) {
+	statement, err := repository.db.PrepareContext(ctx, `
+	with inserted as (
+		INSERT INTO posts (title, content, author_id) VALUES ($1, $2, $3) RETURNING id, createdAt
+	), tagged as (
+		insert into post_tags (post_id, tag) select id, unnest($4::text[]) from inserted
+	), mentioned as (
+		insert into post_mentions (post_id, user_id, position, length)
+		select i.id, u.id, m.position, m.length
+		from inserted i
+		cross join unnest($5::text[], $6::int[], $7::int[]) m(nick, position, length)
+		join users u on u.nick = m.nick
+		returning user_id, position
+	)
+	select id, createdAt, `+resolvedMentions+` from inserted;`)
 	if err != nil {
-		return err
+		return models.Post{}, err
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(post.Title, post.Content, post.AuthorId)
+	var resolved []byte
+	nicks, positions, lengths := mentionArrays(post.Mentions)
+	err = statement.QueryRowContext(ctx,
+		post.Title, post.Content, post.AuthorId, pq.Array(post.Tags), nicks, positions, lengths,
+	).Scan(&post.Id, &post.CreatedAt, &resolved)
 	if err != nil {
-		return err
+		return models.Post{}, err
 	}
 
-	repository.redis.Del(context.Background(), "posts")
+	if post.Mentions, err = resolveMentions(post.Mentions, resolved); err != nil {
+		return models.Post{}, err
+	}
 
-	return nil
+	invalidatePostPages(repository.redis)
+	timelines.Push(post.Id, post.AuthorId, post.CreatedAt)
+	NewTagRepository(repository.redis).Record(context.Background(), post.Tags, post.CreatedAt)
+
+	return post, nil
 }
 
-func (repository Posts) GetPostById(id uuid.UUID) (models.Post, error) {
-	lines, err := repository.db.Query(`
-	select p.*, u.nick from
+func (repository Posts) GetPostById(ctx context.Context, id uuid.UUID) (models.Post, error) {
+	lines, err := repository.db.QueryContext(ctx, `
+	select `+postColumns+` from
 	posts p inner join users u
 	on u.id = p.author_id where p.id = $1
 	`, id)
 	if err != nil {
 		return models.Post{}, err
 	}
+
+	defer lines.Close()
+
 	var post models.Post
 	if lines.Next() {
-
-		err := lines.Scan(
-			&post.Id,
-			&post.Title,
-			&post.Content,
-			&post.AuthorId,
-			&post.Likes,
-			&post.CreatedAt,
-			&post.AuthorNick,
-		)
+		post, err = scanPost(lines)
 		if err != nil {
 			return models.Post{}, err
 		}
+	} else {
+		return models.Post{}, ErrPostNotFound
 	}
 
-	return post, nil
+	posts := []models.Post{post}
+	if err := repository.countReactions(ctx, posts); err != nil {
+		return models.Post{}, err
+	}
+
+	return posts[0], nil
 }
 
-func (repository Posts) GetPosts() ([]models.Post, error) {
-	cachedPosts, err := repository.redis.Get(context.Background(), "posts").Result()
-	if err == nil {
-		var posts []models.Post
-		err := json.Unmarshal([]byte(cachedPosts), &posts)
+// postsGenerationKey counts the writes to the posts. The cached first pages are
+// keyed by it, so a page queried before a write and cached after it lands under
+// a generation nobody reads anymore, instead of outliving the invalidation.
+const postsGenerationKey = "posts:generation"
+
+// invalidatePostPages drops every cached first page after a write to the posts.
+func invalidatePostPages(client *redis.Client) {
+	ctx := context.Background()
+	client.Incr(ctx, postsGenerationKey)
+	client.Del(ctx, "posts")
+}
+
+// GetPosts returns a page of posts older than cursor, with the reaction userId
+// gave to each one. Only the first page is cached, as a field of the "posts"
+// hash keyed by limit and the generation read before querying, so every write
+// invalidates all cached pages, including the ones being queried, through
+// invalidatePostPages. The per-user reaction is always resolved from the
+// database.
+func (repository Posts) GetPosts(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error) {
+	generation, _ := repository.redis.Get(ctx, postsGenerationKey).Int64()
+	cacheField := fmt.Sprintf("first:%d:%d", limit, generation)
+
+	if cursor.IsZero() {
+		cachedPage, err := repository.redis.HGet(ctx, "posts", cacheField).Result()
 		if err == nil {
-			return posts, nil
+			var page models.PostPage
+			err := json.Unmarshal([]byte(cachedPage), &page)
+			if err == nil {
+				metrics.CacheHit("posts")
+				return page, repository.markReactions(ctx, userId, page.Posts)
+			}
+		}
+		metrics.CacheMiss("posts")
+	}
+
+	page, err := repository.queryPostsPage(ctx, "", nil, cursor, limit)
+	if err != nil {
+		return models.PostPage{}, err
+	}
+
+	if cursor.IsZero() {
+		pageJson, _ := json.Marshal(page)
+		repository.redis.HSet(ctx, "posts", cacheField, pageJson)
+		repository.redis.Expire(ctx, "posts", 10*time.Minute)
+	}
+
+	return page, repository.markReactions(ctx, userId, page.Posts)
+}
+
+// GetTimeline returns a page of posts written by the accounts userId follows.
+// Pages are read from the user's fan-out sorted set when it is warm and
+// covers the page, falling back to a SQL join otherwise.
+func (repository Posts) GetTimeline(ctx context.Context, userId uuid.UUID, cursor models.Cursor, limit int) (models.PostPage, error) {
+	entries, ok := repository.cachedTimeline(ctx, userId, cursor, limit+1)
+	if !ok {
+		page, err := repository.queryPostsPage(ctx,
+			"p.author_id in (select followed_id from follows where follower_id = $1)",
+			[]interface{}{userId},
+			cursor, limit,
+		)
+		if err != nil {
+			return models.PostPage{}, err
+		}
+
+		return page, repository.markReactions(ctx, userId, page.Posts)
+	}
+	if len(entries) == 0 {
+		return models.PostPage{Posts: []models.Post{}}, nil
+	}
+
+	var nextCursor string
+	if len(entries) > limit {
+		entries = entries[:limit]
+		last := entries[limit-1]
+		lastId, err := uuid.Parse(last.Member.(string))
+		if err != nil {
+			return models.PostPage{}, err
+		}
+		nextCursor = models.Cursor{CreatedAt: time.UnixMicro(int64(last.Score)), Id: lastId}.Encode()
+	}
+
+	postIds := make([]string, len(entries))
+	for i, entry := range entries {
+		postIds[i] = entry.Member.(string)
+	}
+
+	// Ids of deleted posts may linger in the set; hydrating from SQL skips them.
+	page, err := repository.queryPostsPage(ctx, "p.id = any($1::uuid[])", []interface{}{pq.Array(postIds)}, models.Cursor{}, len(postIds))
+	if err != nil {
+		return models.PostPage{}, err
+	}
+	page.NextCursor = nextCursor
+
+	return page, repository.markReactions(ctx, userId, page.Posts)
+}
+
+// cachedTimeline reads up to count timeline entries older than cursor. ok is
+// false when the key is cold, in which case a rebuild is scheduled, or when the
+// page runs past the capped window and older posts may only exist in SQL. A
+// rebuilt timeline is never empty, since it holds timelines.Marker.
+func (repository Posts) cachedTimeline(ctx context.Context, userId uuid.UUID, cursor models.Cursor, count int) ([]redis.Z, bool) {
+	key := timelines.Key(userId)
+
+	size, err := repository.redis.ZCard(ctx, key).Result()
+	if err != nil {
+		return nil, false
+	}
+	if size == 0 {
+		timelines.Rebuild(userId)
+		return nil, false
+	}
+
+	max := "+inf"
+	var cursorScore float64
+	if !cursor.IsZero() {
+		cursorScore = timelines.Score(cursor.CreatedAt)
+		max = strconv.FormatFloat(cursorScore, 'f', -1, 64)
+	}
+
+	// The range is inclusive of the cursor score, so ask for a few extra
+	// entries to make up for the ones tied with the cursor that are skipped.
+	fetch := int64(count + 8)
+	candidates, err := repository.redis.ZRevRangeByScoreWithScores(ctx, key, &redis.ZRangeBy{
+		Min:   "(0",
+		Max:   max,
+		Count: fetch,
+	}).Result()
+	if err != nil {
+		return nil, false
+	}
+
+	var entries []redis.Z
+	for _, candidate := range candidates {
+		member, _ := candidate.Member.(string)
+		if !cursor.IsZero() && candidate.Score == cursorScore && member >= cursor.Id.String() {
+			continue
+		}
+		entries = append(entries, candidate)
+		if len(entries) == count {
+			return entries, true
+		}
+	}
+
+	if int64(len(candidates)) == fetch || size >= timelines.MaxEntries {
+		return nil, false
+	}
+
+	return entries, true
+}
+
+// queryPostsPage runs the feed query restricted by filter, whose placeholders
+// refer to args, and returns at most limit posts older than cursor.
+func (repository Posts) queryPostsPage(ctx context.Context, filter string, args []interface{}, cursor models.Cursor, limit int) (models.PostPage, error) {
+	var conditions []string
+	if filter != "" {
+		conditions = append(conditions, filter)
+	}
+	if !cursor.IsZero() {
+		args = append(args, cursor.CreatedAt, cursor.Id)
+		conditions = append(conditions, fmt.Sprintf("(p.createdat, p.id) < ($%d, $%d)", len(args)-1, len(args)))
+	}
+	args = append(args, limit+1)
+
+	query := `
+	select ` + postColumns + `
+	from posts p
+	join users u on u.id = p.author_id`
+	if len(conditions) > 0 {
+		query += "\n\twhere " + strings.Join(conditions, " and ")
+	}
+	query += fmt.Sprintf("\n\torder by p.createdat desc, p.id desc\n\tlimit $%d;", len(args))
+
+	lines, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.PostPage{}, err
+	}
+
+	defer lines.Close()
+
+	posts := []models.Post{}
+	for lines.Next() {
+		post, err := scanPost(lines)
+		if err != nil {
+			return models.PostPage{}, err
 		}
+		posts = append(posts, post)
 	}
 
-	lines, err := repository.db.Query(`
-	select p.*, u.nick 
-	from posts p 
-	join users u on u.id = p.author_id
-	order by p.createdat desc;`,
+	if err := lines.Err(); err != nil {
+		return models.PostPage{}, err
+	}
+
+	page := models.PostPage{Posts: posts}
+	if len(posts) > limit {
+		page.Posts = posts[:limit]
+		last := page.Posts[limit-1]
+		page.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+	}
+
+	return page, repository.countReactions(ctx, page.Posts)
+}
+
+// GetByTag returns a page of posts tagged with tag, with the reaction userId
+// gave to each one.
+func (repository Posts) GetByTag(ctx context.Context, userId uuid.UUID, tag string, cursor models.Cursor, limit int) (models.PostPage, error) {
+	page, err := repository.queryPostsPage(ctx,
+		"p.id in (select post_id from post_tags where tag = $1)",
+		[]interface{}{tag},
+		cursor, limit,
+	)
+	if err != nil {
+		return models.PostPage{}, err
+	}
+
+	return page, repository.markReactions(ctx, userId, page.Posts)
+}
+
+// Search returns the posts whose title or content match query, best matches
+// first, with the reaction userId gave to each one. query accepts the web
+// search syntax of websearch_to_tsquery.
+func (repository Posts) Search(ctx context.Context, userId uuid.UUID, query string, limit, offset int) ([]models.Post, error) {
+	lines, err := repository.db.QueryContext(ctx, `
+	select `+postColumns+`
+	from posts p
+	join users u on u.id = p.author_id,
+	websearch_to_tsquery('simple', $1) query
+	where p.search_vector @@ query
+	order by ts_rank(p.search_vector, query) desc, p.createdat desc, p.id desc
+	limit $2 offset $3`,
+		query, limit, offset,
 	)
 	if err != nil {
 		return nil, err
 	}
-	var posts []models.Post
+
+	defer lines.Close()
+
+	posts := []models.Post{}
 	for lines.Next() {
-		var post models.Post
-		err := lines.Scan(
-			&post.Id,
-			&post.Title,
-			&post.Content,
-			&post.AuthorId,
-			&post.Likes,
-			&post.CreatedAt,
-			&post.AuthorNick,
-		)
+		post, err := scanPost(lines)
 		if err != nil {
 			return nil, err
 		}
 		posts = append(posts, post)
 	}
-	if len(posts) > 0 {
-		postsJson, _ := json.Marshal(posts)
-		repository.redis.Set(context.Background(), "posts", postsJson, 10*time.Minute)
+	if err := lines.Err(); err != nil {
+		return nil, err
 	}
 
-	return posts, nil
+	if err := repository.countReactions(ctx, posts); err != nil {
+		return nil, err
+	}
+
+	return posts, repository.markReactions(ctx, userId, posts)
 }
 
-func (repository Posts) Update(id uuid.UUID, post models.Post) error {
-	statement, err := repository.db.Prepare("update posts set title = $1, content = $2 where id = $3")
+// countReactions fills the per-type reaction counts of posts. They are shared
+// by every user, so they are part of the cached pages.
+func (repository Posts) countReactions(ctx context.Context, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	lines, err := repository.db.QueryContext(ctx,
+		"select post_id, type, count(*) from post_reactions where post_id = any($1::uuid[]) group by post_id, type",
+		pq.Array(postIds(posts)),
+	)
 	if err != nil {
 		return err
 	}
 
-	defer statement.Close()
+	defer lines.Close()
 
-	_, err = statement.Exec(post.Title, post.Content, id)
+	counts := make(map[uuid.UUID]map[models.Reaction]uint64)
+	for lines.Next() {
+		var postId uuid.UUID
+		var reaction models.Reaction
+		var count uint64
+		if err := lines.Scan(&postId, &reaction, &count); err != nil {
+			return err
+		}
+		if counts[postId] == nil {
+			counts[postId] = make(map[models.Reaction]uint64)
+		}
+		counts[postId][reaction] = count
+	}
+
+	for i := range posts {
+		posts[i].Reactions = counts[posts[i].Id]
+		if posts[i].Reactions == nil {
+			posts[i].Reactions = map[models.Reaction]uint64{}
+		}
+		posts[i].Likes = posts[i].Reactions[models.ReactionLike]
+	}
+
+	return lines.Err()
+}
+
+// markReactions fills the reaction userId gave to each of posts, if any.
+func (repository Posts) markReactions(ctx context.Context, userId uuid.UUID, posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	lines, err := repository.db.QueryContext(ctx,
+		"select post_id, type from post_reactions where user_id = $1 and post_id = any($2::uuid[])",
+		userId, pq.Array(postIds(posts)),
+	)
 	if err != nil {
 		return err
 	}
 
-	repository.redis.Del(context.Background(), "posts")
+	defer lines.Close()
 
-	return nil
+	reactions := make(map[uuid.UUID]models.Reaction)
+	for lines.Next() {
+		var postId uuid.UUID
+		var reaction models.Reaction
+		if err := lines.Scan(&postId, &reaction); err != nil {
+			return err
+		}
+		reactions[postId] = reaction
+	}
+
+	for i := range posts {
+		posts[i].MyReaction = reactions[posts[i].Id]
+		posts[i].LikedByMe = posts[i].MyReaction == models.ReactionLike
+	}
+
+	return lines.Err()
+}
+
+func postIds(posts []models.Post) []string {
+	ids := make([]string, len(posts))
+	for i, post := range posts {
+		ids[i] = post.Id.String()
+	}
+	return ids
+}
+
+// Update rewrites the post and keeps its post_tags and post_mentions rows in
+// sync with the new content. The tags the edit adds are recorded as trending
+// now. It returns the post with its resolved mentions, or ErrPostNotFound when
+// there is no post with that id.
+func (repository Posts) Update(ctx context.Context, id uuid.UUID, post models.Post) (models.Post, error) {
+	statement, err := repository.db.PrepareContext(ctx, `
+	with untagged as (
+		delete from post_tags where post_id = $3 and tag <> all($4::text[])
+	), tagged as (
+		insert into post_tags (post_id, tag) select $3, unnest($4::text[])
+		on conflict do nothing
+		returning tag
+	), mentioned as (
+		select u.id user_id, m.position, m.length
+		from unnest($5::text[], $6::int[], $7::int[]) m(nick, position, length)
+		join users u on u.nick = m.nick
+	), unmentioned as (
+		delete from post_mentions where post_id = $3 and position not in (select position from mentioned)
+	), remention as (
+		insert into post_mentions (post_id, user_id, position, length)
+		select $3, user_id, position, length from mentioned
+		on conflict (post_id, position) do update set user_id = excluded.user_id, length = excluded.length
+	)
+	update posts set title = $1, content = $2 where id = $3
+	returning id, author_id, createdAt, `+resolvedMentions+`,
+	(select coalesce(array_agg(tag), '{}') from tagged)`)
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	defer statement.Close()
+
+	var resolved []byte
+	var addedTags []string
+	nicks, positions, lengths := mentionArrays(post.Mentions)
+	err = statement.QueryRowContext(ctx,
+		post.Title, post.Content, id, pq.Array(post.Tags), nicks, positions, lengths,
+	).Scan(&post.Id, &post.AuthorId, &post.CreatedAt, &resolved, pq.Array(&addedTags))
+	if err == sql.ErrNoRows {
+		return models.Post{}, ErrPostNotFound
+	}
+	if err != nil {
+		return models.Post{}, err
+	}
+
+	if post.Mentions, err = resolveMentions(post.Mentions, resolved); err != nil {
+		return models.Post{}, err
+	}
+
+	invalidatePostPages(repository.redis)
+	NewTagRepository(repository.redis).Record(context.Background(), addedTags, time.Now())
+
+	return post, nil
 }
 
-func (repository Posts) Delete(id uuid.UUID) error {
-	statement, err := repository.db.Prepare("delete from posts where id = $1")
+// Delete removes the post, or returns ErrPostNotFound when there is no post with
+// that id.
+func (repository Posts) Delete(ctx context.Context, id uuid.UUID) error {
+	statement, err := conn(ctx, repository.db).PrepareContext(ctx, "delete from posts where id = $1 returning author_id")
 	if err != nil {
 		return err
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(id)
+	var authorId uuid.UUID
+	err = statement.QueryRowContext(ctx, id).Scan(&authorId)
+	if err == sql.ErrNoRows {
+		return ErrPostNotFound
+	}
 	if err != nil {
 		return err
 	}
-	repository.redis.Del(context.Background(), "posts")
+	afterCommit(ctx, func() {
+		invalidatePostPages(repository.redis)
+		timelines.Remove(id, authorId)
+	})
 
 	return nil
 }
 
-func (repository Posts) Like(id uuid.UUID) error {
-	statement, err := repository.db.Prepare("update posts set likes = likes + 1 where id = $1")
+// React sets the reaction of userId to postId, replacing any previous one, so a
+// user holds at most one reaction per post.
+func (repository Posts) React(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error {
+	statement, err := repository.db.PrepareContext(ctx, `
+	insert into post_reactions (user_id, post_id, type) values ($1, $2, $3)
+	on conflict (user_id, post_id) do update set type = excluded.type, createdAt = CURRENT_TIMESTAMP
+	`)
 	if err != nil {
 		return err
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(id)
+	_, err = statement.ExecContext(ctx, userId, postId, reaction)
 	if err != nil {
 		return err
 	}
 
-	repository.redis.Del(context.Background(), "posts")
+	invalidatePostPages(repository.redis)
 	return nil
 }
 
-func (repository Posts) Dislike(id uuid.UUID) error {
-	statement, err := repository.db.Prepare(`
-	update posts set likes =
-	CASE 
-		WHEN likes > 0 THEN likes - 1
-		ELSE likes 
-	END 
-	where id = $1
-	`)
+// RemoveReaction removes the reaction of userId to postId when it is of the
+// given type. Removing a reaction that is not there is a no-op.
+func (repository Posts) RemoveReaction(ctx context.Context, userId, postId uuid.UUID, reaction models.Reaction) error {
+	statement, err := repository.db.PrepareContext(ctx,
+		"delete from post_reactions where user_id = $1 and post_id = $2 and type = $3",
+	)
 	if err != nil {
 		return err
 	}
 
 	defer statement.Close()
 
-	_, err = statement.Exec(id)
+	_, err = statement.ExecContext(ctx, userId, postId, reaction)
 	if err != nil {
 		return err
 	}
-	repository.redis.Del(context.Background(), "posts")
+	invalidatePostPages(repository.redis)
 
 	return nil
 }
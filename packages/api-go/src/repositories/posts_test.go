@@ -1,14 +1,19 @@
 package repositories_test
 
 import (
+	"context"
+	"encoding/json"
 	"testing"
 	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/go-redis/redismock/v9"
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 	"github.com/otaviopontes/api-go/src/models"
 	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/otaviopontes/api-go/src/timelines"
+	goredis "github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -22,17 +27,31 @@ func TestCreatePost(t *testing.T) {
 
 	post := models.Post{
 		Title:    "First Post",
-		Content:  "This is the content of the first post",
+		Content:  "This is the content of the first #post, hi @alice and @ghost",
 		AuthorId: uuid.New(),
+		Tags:     []string{"post"},
+		Mentions: []models.Mention{
+			{Nick: "alice", Offset: 44, Length: 6},
+			{Nick: "ghost", Offset: 55, Length: 6},
+		},
 	}
-
-	mock.ExpectPrepare("INSERT INTO posts").
-		ExpectExec().
-		WithArgs(post.Title, post.Content, post.AuthorId).
-		WillReturnResult(sqlmock.NewResult(1, 1))
-
-	err = postRepo.Create(post.AuthorId, post)
+	postId := uuid.New()
+	aliceId := uuid.New()
+
+	mock.ExpectPrepare(`INSERT INTO posts .*\s+insert into post_tags \(post_id, tag\) select id, unnest\(\$4::text\[\]\) from inserted`+
+		`(.|\s)*insert into post_mentions`).
+		ExpectQuery().
+		WithArgs(
+			post.Title, post.Content, post.AuthorId, pq.Array(post.Tags),
+			pq.Array([]string{"alice", "ghost"}), pq.Array([]int64{44, 55}), pq.Array([]int64{6, 6}),
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "createdAt", "mentions"}).
+			AddRow(postId, time.Now(), `[{"userId": "`+aliceId.String()+`", "offset": 44}]`))
+
+	created, err := postRepo.Create(context.Background(), post.AuthorId, post)
 	assert.NoError(t, err)
+	assert.Equal(t, postId, created.Id)
+	assert.Equal(t, []models.Mention{{UserId: aliceId, Nick: "alice", Offset: 44, Length: 6}}, created.Mentions)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -44,18 +63,48 @@ func TestGetPostById(t *testing.T) {
 	postRepo := repositories.NewPostRepository(db, redis)
 	postId := uuid.New()
 
-	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "likes", "created_at", "author_nick"}).
-		AddRow(postId, "First Post", "This is the content of the first post", uuid.New(), 0, time.Now(), "author_nick")
+	mentionedId := uuid.New()
 
-	mock.ExpectQuery("select p.*, u.nick from").
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(postId, "First Post", "This is the content of the first post", uuid.New(), time.Now(), "author_nick", 2,
+			`[{"userId": "`+mentionedId.String()+`", "nick": "alice", "offset": 0, "length": 6}]`)
+
+	mock.ExpectQuery("select p.id, p.title, p.content, p.author_id, p.createdat, u.nick").
 		WithArgs(postId).
 		WillReturnRows(rows)
 
-	post, err := postRepo.GetPostById(postId)
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}).
+			AddRow(postId, "like", 4).
+			AddRow(postId, "sad", 1))
+
+	post, err := postRepo.GetPostById(context.Background(), postId)
 	assert.NoError(t, err)
 	assert.Equal(t, postId, post.Id)
 	assert.Equal(t, "First Post", post.Title)
 	assert.Equal(t, "This is the content of the first post", post.Content)
+	assert.Equal(t, uint64(2), post.CommentsCount)
+	assert.Equal(t, []models.Mention{{UserId: mentionedId, Nick: "alice", Offset: 0, Length: 6}}, post.Mentions)
+	assert.Equal(t, uint64(4), post.Likes)
+	assert.Equal(t, map[models.Reaction]uint64{models.ReactionLike: 4, models.ReactionSad: 1}, post.Reactions)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetMissingPostById(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, _ := redismock.NewClientMock()
+	postRepo := repositories.NewPostRepository(db, redis)
+	postId := uuid.New()
+
+	mock.ExpectQuery("select p.id, p.title, p.content, p.author_id, p.createdat, u.nick").
+		WithArgs(postId).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	_, err = postRepo.GetPostById(context.Background(), postId)
+	assert.ErrorIs(t, err, repositories.ErrPostNotFound)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -67,20 +116,42 @@ func TestGetPosts(t *testing.T) {
 
 	postRepo := repositories.NewPostRepository(db, redis)
 
-	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "likes", "created_at", "author_nick"}).
-		AddRow(uuid.New(), "First Post", "This is the content of the first post", uuid.New(), 0, time.Now(), "author_nick").
-		AddRow(uuid.New(), "Second Post", "This is the content of the second post", uuid.New(), 10, time.Now(), "another_author")
+	userId := uuid.New()
+	likedPostId := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(likedPostId, "First Post", "This is the content of the first post", uuid.New(), time.Now(), "author_nick", 0, "[]").
+		AddRow(uuid.New(), "Second Post", "This is the content of the second post", uuid.New(), time.Now(), "another_author", 0, "[]")
 
-	mock.ExpectQuery("select p.*, u.nick from posts").
+	mock.ExpectQuery("select p.id, .*, u.nick, .* from posts").
+		WithArgs(21).
 		WillReturnRows(rows)
 
-	posts, err := postRepo.GetPosts()
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WithArgs(sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}).
+			AddRow(likedPostId, "like", 1).
+			AddRow(likedPostId, "love", 2))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WithArgs(userId, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}).AddRow(likedPostId, "like"))
+
+	page, err := postRepo.GetPosts(context.Background(), userId, models.Cursor{}, 20)
 	assert.NoError(t, err)
-	assert.Len(t, posts, 2)
+	assert.Len(t, page.Posts, 2)
+	assert.Empty(t, page.NextCursor)
+	assert.True(t, page.Posts[0].LikedByMe)
+	assert.Equal(t, models.ReactionLike, page.Posts[0].MyReaction)
+	assert.Equal(t, uint64(1), page.Posts[0].Likes)
+	assert.Equal(t, uint64(2), page.Posts[0].Reactions[models.ReactionLove])
+	assert.False(t, page.Posts[1].LikedByMe)
+	assert.Empty(t, page.Posts[1].MyReaction)
+	assert.Empty(t, page.Posts[1].Reactions)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestUpdatePost(t *testing.T) {
+func TestGetPostsWithCursor(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	redis, _ := redismock.NewClientMock()
 	assert.NoError(t, err)
@@ -88,20 +159,258 @@ func TestUpdatePost(t *testing.T) {
 
 	postRepo := repositories.NewPostRepository(db, redis)
 
+	cursor := models.Cursor{CreatedAt: time.Now(), Id: uuid.New()}
+	secondId := uuid.New()
+	secondCreatedAt := cursor.CreatedAt.Add(-2 * time.Minute)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(uuid.New(), "First Post", "This is the content of the first post", uuid.New(), cursor.CreatedAt.Add(-time.Minute), "author_nick", 0, "[]").
+		AddRow(secondId, "Second Post", "This is the content of the second post", uuid.New(), secondCreatedAt, "author_nick", 0, "[]").
+		AddRow(uuid.New(), "Third Post", "This is the content of the third post", uuid.New(), cursor.CreatedAt.Add(-3*time.Minute), "author_nick", 0, "[]")
+
+	mock.ExpectQuery(`where \(p.createdat, p.id\) < \(\$1, \$2\)`).
+		WithArgs(cursor.CreatedAt, cursor.Id, 3).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}))
+
+	page, err := postRepo.GetPosts(context.Background(), uuid.New(), cursor, 2)
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 2)
+
+	next, err := models.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, secondId, next.Id)
+	assert.True(t, secondCreatedAt.Equal(next.CreatedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetPostsFromCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, redisMock := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	cachedPage, _ := json.Marshal(models.PostPage{
+		Posts: []models.Post{{Id: uuid.New(), Title: "Cached Post", Content: "This post came from Redis"}},
+	})
+	redisMock.ExpectGet("posts:generation").SetVal("3")
+	redisMock.ExpectHGet("posts", "first:20:3").SetVal(string(cachedPage))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}))
+
+	page, err := postRepo.GetPosts(context.Background(), uuid.New(), models.Cursor{}, 20)
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Equal(t, "Cached Post", page.Posts[0].Title)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetTimeline(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	userId := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(uuid.New(), "Followed Post", "Written by someone the user follows", uuid.New(), time.Now(), "followed", 0, "[]")
+
+	mock.ExpectQuery(`where p.author_id in \(select followed_id from follows where follower_id = \$1\)`).
+		WithArgs(userId, 21).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WithArgs(userId, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}))
+
+	page, err := postRepo.GetTimeline(context.Background(), userId, models.Cursor{}, 20)
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Equal(t, "followed", page.Posts[0].AuthorNick)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTimelineFromCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, redisMock := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	userId := uuid.New()
+	newestId := uuid.New()
+	olderId := uuid.New()
+	newestCreatedAt := time.Now().Truncate(time.Microsecond)
+
+	redisMock.ExpectZCard(timelines.Key(userId)).SetVal(2)
+	redisMock.ExpectZRevRangeByScoreWithScores(timelines.Key(userId), &goredis.ZRangeBy{Min: "(0", Max: "+inf", Count: 10}).
+		SetVal([]goredis.Z{
+			{Score: timelines.Score(newestCreatedAt), Member: newestId.String()},
+			{Score: timelines.Score(newestCreatedAt.Add(-time.Minute)), Member: olderId.String()},
+		})
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(newestId, "Newest Post", "Pushed by the fan-out workers", uuid.New(), newestCreatedAt, "followed", 0, "[]")
+
+	mock.ExpectQuery(`where p.id = any\(\$1::uuid\[\]\)`).
+		WithArgs(sqlmock.AnyArg(), 2).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}))
+
+	page, err := postRepo.GetTimeline(context.Background(), userId, models.Cursor{}, 1)
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Equal(t, newestId, page.Posts[0].Id)
+
+	next, err := models.DecodeCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, newestId, next.Id)
+	assert.True(t, newestCreatedAt.Equal(next.CreatedAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetEmptyTimelineFromCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, redisMock := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	userId := uuid.New()
+
+	// A rebuilt timeline of a user who follows nobody only holds the marker,
+	// so it is served as warm without querying SQL or scheduling a rebuild.
+	redisMock.ExpectZCard(timelines.Key(userId)).SetVal(1)
+	redisMock.ExpectZRevRangeByScoreWithScores(timelines.Key(userId), &goredis.ZRangeBy{Min: "(0", Max: "+inf", Count: 29}).
+		SetVal([]goredis.Z{})
+
+	page, err := postRepo.GetTimeline(context.Background(), userId, models.Cursor{}, 20)
+	assert.NoError(t, err)
+	assert.Empty(t, page.Posts)
+	assert.Empty(t, page.NextCursor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestGetPostsByTag(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(uuid.New(), "Tagged Post", "Learning #golang today", uuid.New(), time.Now(), "author_nick", 0, "[]")
+
+	mock.ExpectQuery(`where p.id in \(select post_id from post_tags where tag = \$1\)`).
+		WithArgs("golang", 21).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}))
+
+	page, err := postRepo.GetByTag(context.Background(), uuid.New(), "golang", models.Cursor{}, 20)
+	assert.NoError(t, err)
+	assert.Len(t, page.Posts, 1)
+	assert.Equal(t, []string{"golang"}, page.Posts[0].Tags)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchPosts(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, _ := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	userId := uuid.New()
 	postId := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "title", "content", "author_id", "created_at", "author_nick", "comments_count", "mentions"}).
+		AddRow(postId, "Go tips", "Searching posts with Postgres", uuid.New(), time.Now(), "author_nick", 0, "[]")
+
+	mock.ExpectQuery(`where p.search_vector @@ query\s+order by ts_rank\(p.search_vector, query\) desc`).
+		WithArgs("postgres", 21, 0).
+		WillReturnRows(rows)
+
+	mock.ExpectQuery(`select post_id, type, count\(\*\) from post_reactions`).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type", "count"}))
+
+	mock.ExpectQuery("select post_id, type from post_reactions where user_id =").
+		WithArgs(userId, sqlmock.AnyArg()).
+		WillReturnRows(sqlmock.NewRows([]string{"post_id", "type"}).AddRow(postId, "love"))
+
+	posts, err := postRepo.Search(context.Background(), userId, "postgres", 21, 0)
+	assert.NoError(t, err)
+	assert.Len(t, posts, 1)
+	assert.Equal(t, models.ReactionLove, posts[0].MyReaction)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdatePost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, redisMock := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	postId := uuid.New()
+	authorId := uuid.New()
 	post := models.Post{
-		Title:   "Updated Title",
-		Content: "Updated Content",
+		Title:    "Updated Title",
+		Content:  "Updated #Content for @bob",
+		Tags:     []string{"content"},
+		Mentions: []models.Mention{{Nick: "bob", Offset: 21, Length: 4}},
 	}
 
-	mock.ExpectPrepare("update posts set title").
-		ExpectExec().
-		WithArgs(post.Title, post.Content, postId).
-		WillReturnResult(sqlmock.NewResult(1, 1))
-
-	err = postRepo.Update(postId, post)
+	mock.ExpectPrepare(`delete from post_tags where post_id = \$3 and tag <> all\(\$4::text\[\]\)(.|\s)*update posts set title`).
+		ExpectQuery().
+		WithArgs(
+			post.Title, post.Content, postId, pq.Array(post.Tags),
+			pq.Array([]string{"bob"}), pq.Array([]int64{21}), pq.Array([]int64{4}),
+		).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "author_id", "createdAt", "mentions", "added_tags"}).
+			AddRow(postId, authorId, time.Now(), "[]", "{content}"))
+	redisMock.ExpectIncr("posts:generation").SetVal(1)
+	redisMock.ExpectDel("posts").SetVal(1)
+	redisMock.Regexp().ExpectZIncrBy(`tags:trending:\d+`, 1, "content").SetVal(1)
+	redisMock.Regexp().ExpectExpire(`tags:trending:\d+`, repositories.MaxTrendingWindow+time.Hour).SetVal(true)
+
+	updated, err := postRepo.Update(context.Background(), postId, post)
 	assert.NoError(t, err)
+	assert.Equal(t, authorId, updated.AuthorId)
+	assert.Equal(t, []models.Mention{}, updated.Mentions)
 	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
 }
 
 func TestDeletePost(t *testing.T) {
@@ -115,16 +424,16 @@ func TestDeletePost(t *testing.T) {
 	postId := uuid.New()
 
 	mock.ExpectPrepare("delete from posts where id").
-		ExpectExec().
+		ExpectQuery().
 		WithArgs(postId).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}).AddRow(uuid.New()))
 
-	err = postRepo.Delete(postId)
+	err = postRepo.Delete(context.Background(), postId)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
-func TestLikePost(t *testing.T) {
+func TestDeleteMissingPost(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	redis, _ := redismock.NewClientMock()
 	assert.NoError(t, err)
@@ -134,17 +443,41 @@ func TestLikePost(t *testing.T) {
 
 	postId := uuid.New()
 
-	mock.ExpectPrepare(`update posts set likes = likes \+ 1 where id = \$1`).
-		ExpectExec().
+	mock.ExpectPrepare("delete from posts where id").
+		ExpectQuery().
 		WithArgs(postId).
+		WillReturnRows(sqlmock.NewRows([]string{"author_id"}))
+
+	err = postRepo.Delete(context.Background(), postId)
+	assert.ErrorIs(t, err, repositories.ErrPostNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestReactToPost(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	redis, redisMock := redismock.NewClientMock()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	postRepo := repositories.NewPostRepository(db, redis)
+
+	userId := uuid.New()
+	postId := uuid.New()
+
+	mock.ExpectPrepare(`insert into post_reactions \(user_id, post_id, type\) values \(\$1, \$2, \$3\)\s+on conflict \(user_id, post_id\) do update`).
+		ExpectExec().
+		WithArgs(userId, postId, models.ReactionLove).
 		WillReturnResult(sqlmock.NewResult(1, 1))
+	redisMock.ExpectIncr("posts:generation").SetVal(4)
+	redisMock.ExpectDel("posts").SetVal(1)
 
-	err = postRepo.Like(postId)
+	err = postRepo.React(context.Background(), userId, postId, models.ReactionLove)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
 }
 
-func TestDislikePost(t *testing.T) {
+func TestRemovePostReaction(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	redis, _ := redismock.NewClientMock()
 	assert.NoError(t, err)
@@ -152,21 +485,15 @@ func TestDislikePost(t *testing.T) {
 
 	postRepo := repositories.NewPostRepository(db, redis)
 
+	userId := uuid.New()
 	postId := uuid.New()
 
-	mock.ExpectPrepare(`
-	update posts set likes =
-	CASE 
-		WHEN likes > 0 THEN likes - 1
-		ELSE likes 
-	END 
-	where id = \$1
-	`).
+	mock.ExpectPrepare(`delete from post_reactions where user_id = \$1 and post_id = \$2 and type = \$3`).
 		ExpectExec().
-		WithArgs(postId).
+		WithArgs(userId, postId, models.ReactionLike).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = postRepo.Dislike(postId)
+	err = postRepo.RemoveReaction(context.Background(), userId, postId, models.ReactionLike)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("the refresh token is invalid or expired")
+	ErrRefreshTokenReused  = errors.New("the refresh token was already used, the sessions it belongs to are revoked")
+)
+
+type SessionRepository interface {
+	CreateRefreshToken(ctx context.Context, userId, familyId uuid.UUID, tokenHash string, expiresAt time.Time) error
+	RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (uuid.UUID, error)
+	RevokeRefreshToken(ctx context.Context, userId uuid.UUID, tokenHash string) error
+	RevokeUser(ctx context.Context, userId uuid.UUID) error
+	DenyAccessToken(ctx context.Context, tokenId string, expiresAt time.Time) error
+	IsRevoked(ctx context.Context, userId uuid.UUID, tokenId string, issuedAt time.Time) (bool, error)
+}
+
+// DeniedKey returns the Redis key marking the access token tokenId as revoked.
+func DeniedKey(tokenId string) string {
+	return fmt.Sprintf("auth:denied:%s", tokenId)
+}
+
+// RevokedKey returns the Redis key holding the time before which every access
+// token of userId is revoked.
+func RevokedKey(userId uuid.UUID) string {
+	return fmt.Sprintf("auth:revoked:%s", userId)
+}
+
+// Sessions keeps the refresh tokens in Postgres, only by their SHA-256 hash,
+// and the revoked access tokens in Redis until they would have expired anyway,
+// leeway included, since tokens are still accepted that long after their exp.
+// Refresh tokens are single use: each one is exchanged for a new one of the
+// same family, and presenting a used one again revokes the whole family, as it
+// means the token leaked.
+type Sessions struct {
+	db             *sql.DB
+	redis          *redis.Client
+	accessTokenTTL time.Duration
+	leeway         time.Duration
+}
+
+func NewSessionRepository(db *sql.DB, redis *redis.Client, accessTokenTTL, leeway time.Duration) *Sessions {
+	return &Sessions{db, redis, accessTokenTTL, leeway}
+}
+
+func (repository Sessions) CreateRefreshToken(ctx context.Context, userId, familyId uuid.UUID, tokenHash string, expiresAt time.Time) error {
+	statement, err := repository.db.PrepareContext(ctx,
+		"insert into refresh_tokens (user_id, family_id, token_hash, expiresAt) values ($1, $2, $3, $4)",
+	)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(ctx, userId, familyId, tokenHash, expiresAt)
+	return err
+}
+
+// RotateRefreshToken marks the refresh token hashed as tokenHash used and
+// stores newTokenHash in its family, returning the user they belong to.
+func (repository Sessions) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (uuid.UUID, error) {
+	var userId uuid.UUID
+	err := repository.db.QueryRowContext(ctx, `
+	with used as (
+		update refresh_tokens set usedAt = CURRENT_TIMESTAMP
+		where token_hash = $1 and usedAt is null and revokedAt is null and expiresAt > CURRENT_TIMESTAMP
+		returning user_id, family_id
+	), issued as (
+		insert into refresh_tokens (user_id, family_id, token_hash, expiresAt)
+		select user_id, family_id, $2, $3 from used
+	)
+	select user_id from used`,
+		tokenHash, newTokenHash, expiresAt,
+	).Scan(&userId)
+	if err == nil {
+		return userId, nil
+	}
+	if err != sql.ErrNoRows {
+		return uuid.Nil, err
+	}
+
+	result, err := repository.db.ExecContext(ctx, `
+	update refresh_tokens set revokedAt = CURRENT_TIMESTAMP
+	where revokedAt is null
+	and family_id = (select family_id from refresh_tokens where token_hash = $1 and usedAt is not null)`,
+		tokenHash,
+	)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	revoked, err := result.RowsAffected()
+	if err != nil {
+		return uuid.Nil, err
+	}
+	if revoked > 0 {
+		return uuid.Nil, ErrRefreshTokenReused
+	}
+
+	return uuid.Nil, ErrInvalidRefreshToken
+}
+
+// RevokeRefreshToken revokes the family of the refresh token hashed as
+// tokenHash, when it belongs to userId.
+func (repository Sessions) RevokeRefreshToken(ctx context.Context, userId uuid.UUID, tokenHash string) error {
+	_, err := repository.db.ExecContext(ctx, `
+	update refresh_tokens set revokedAt = CURRENT_TIMESTAMP
+	where revokedAt is null
+	and family_id = (select family_id from refresh_tokens where token_hash = $1 and user_id = $2)`,
+		tokenHash, userId,
+	)
+	return err
+}
+
+// RevokeUser signs userId out of every session: their refresh tokens are
+// revoked, as well as the access tokens issued until now.
+func (repository Sessions) RevokeUser(ctx context.Context, userId uuid.UUID) error {
+	_, err := conn(ctx, repository.db).ExecContext(ctx,
+		"update refresh_tokens set revokedAt = CURRENT_TIMESTAMP where user_id = $1 and revokedAt is null",
+		userId,
+	)
+	if err != nil {
+		return err
+	}
+
+	// Access tokens only carry their issue time to the second, so the ones
+	// issued during this second are revoked too.
+	return repository.redis.Set(ctx, RevokedKey(userId), time.Now().Unix(), repository.accessTokenTTL+repository.leeway).Err()
+}
+
+// DenyAccessToken revokes the access token tokenId until it expires and the
+// leeway after that has passed.
+func (repository Sessions) DenyAccessToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	ttl := time.Until(expiresAt) + repository.leeway
+	if ttl <= 0 {
+		return nil
+	}
+
+	return repository.redis.Set(ctx, DeniedKey(tokenId), 1, ttl).Err()
+}
+
+// IsRevoked reports whether the access token tokenId of userId, issued at
+// issuedAt, was denied or issued before its user was signed out everywhere.
+func (repository Sessions) IsRevoked(ctx context.Context, userId uuid.UUID, tokenId string, issuedAt time.Time) (bool, error) {
+	pipe := repository.redis.Pipeline()
+	denied := pipe.Exists(ctx, DeniedKey(tokenId))
+	revokedAt := pipe.Get(ctx, RevokedKey(userId))
+	if _, err := pipe.Exec(ctx); err != nil && err != redis.Nil {
+		return false, err
+	}
+
+	if denied.Val() > 0 {
+		return true, nil
+	}
+
+	if revokedAt.Err() == redis.Nil {
+		return false, nil
+	}
+	before, err := strconv.ParseInt(revokedAt.Val(), 10, 64)
+	if err != nil {
+		return false, err
+	}
+
+	return issuedAt.Unix() <= before, nil
+}
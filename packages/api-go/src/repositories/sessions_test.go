@@ -0,0 +1,126 @@
+package repositories_test
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/go-redis/redismock/v9"
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/repositories"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRotateRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, _ := redismock.NewClientMock()
+
+	sessionRepo := repositories.NewSessionRepository(db, redis, 15*time.Minute, 30*time.Second)
+
+	userId := uuid.New()
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery("update refresh_tokens set usedAt").
+		WithArgs("old-hash", "new-hash", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(userId))
+
+	rotatedFor, err := sessionRepo.RotateRefreshToken(context.Background(), "old-hash", "new-hash", expiresAt)
+
+	assert.NoError(t, err)
+	assert.Equal(t, userId, rotatedFor)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestRotateReusedRefreshToken(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, _ := redismock.NewClientMock()
+
+	sessionRepo := repositories.NewSessionRepository(db, redis, 15*time.Minute, 30*time.Second)
+
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectQuery("update refresh_tokens set usedAt").
+		WithArgs("used-hash", "new-hash", expiresAt).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}))
+	mock.ExpectExec("update refresh_tokens set revokedAt").
+		WithArgs("used-hash").
+		WillReturnResult(sqlmock.NewResult(0, 2))
+
+	_, err = sessionRepo.RotateRefreshToken(context.Background(), "used-hash", "new-hash", expiresAt)
+
+	assert.ErrorIs(t, err, repositories.ErrRefreshTokenReused)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsRevoked(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	sessionRepo := repositories.NewSessionRepository(db, redis, 15*time.Minute, 30*time.Second)
+
+	userId := uuid.New()
+	revokedAt := time.Now().Add(-time.Minute)
+
+	redisMock.ExpectExists(repositories.DeniedKey("token-id")).SetVal(0)
+	redisMock.ExpectGet(repositories.RevokedKey(userId)).SetVal(strconv.FormatInt(revokedAt.Unix(), 10))
+
+	revoked, err := sessionRepo.IsRevoked(context.Background(), userId, "token-id", revokedAt.Add(-time.Hour))
+
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestDenyAccessTokenWithinLeeway(t *testing.T) {
+	db, _, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	sessionRepo := repositories.NewSessionRepository(db, redis, 15*time.Minute, 30*time.Second)
+
+	// The token expired 10 seconds ago but is accepted for 20 more, so it has
+	// to stay denied until then.
+	redisMock.CustomMatch(func(expected, actual []interface{}) error {
+		assert.Equal(t, []interface{}{"set", repositories.DeniedKey("token-id"), 1}, actual[:3])
+		unit := map[interface{}]time.Duration{"px": time.Millisecond, "ex": time.Second}[actual[3]]
+		ttl := time.Duration(actual[4].(int64)) * unit
+		assert.True(t, ttl > 15*time.Second && ttl <= 20*time.Second, ttl)
+		return nil
+	}).ExpectSet(repositories.DeniedKey("token-id"), 1, 20*time.Second-time.Millisecond).SetVal("OK")
+
+	err = sessionRepo.DenyAccessToken(context.Background(), "token-id", time.Now().Add(-10*time.Second))
+
+	assert.NoError(t, err)
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
+
+func TestRevokeUserCoversLeeway(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+	redis, redisMock := redismock.NewClientMock()
+
+	sessionRepo := repositories.NewSessionRepository(db, redis, 15*time.Minute, 30*time.Second)
+
+	userId := uuid.New()
+
+	mock.ExpectExec("update refresh_tokens set revokedAt").
+		WithArgs(userId).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	redisMock.Regexp().ExpectSet(repositories.RevokedKey(userId), `\d+`, 15*time.Minute+30*time.Second).SetVal("OK")
+
+	err = sessionRepo.RevokeUser(context.Background(), userId)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+	assert.NoError(t, redisMock.ExpectationsWereMet())
+}
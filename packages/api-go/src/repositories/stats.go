@@ -0,0 +1,66 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+type StatsRepository interface {
+	Get(ctx context.Context) (models.Stats, error)
+}
+
+type Stats struct {
+	db *sql.DB
+}
+
+func NewStatsRepository(db *sql.DB) *Stats {
+	return &Stats{db}
+}
+
+// Get counts the rows of the main tables. The counts are exact, which is fine
+// for an admin dashboard but should not be polled.
+func (repository Stats) Get(ctx context.Context) (models.Stats, error) {
+	var stats models.Stats
+	err := repository.db.QueryRowContext(ctx, `
+	select
+	(select count(*) from users),
+	(select count(*) from users where suspendedAt is not null),
+	(select count(*) from posts),
+	(select count(*) from posts where createdAt > CURRENT_TIMESTAMP - interval '1 day'),
+	(select count(*) from comments),
+	(select count(*) from post_reactions),
+	(select count(*) from follows)`,
+	).Scan(
+		&stats.Users,
+		&stats.SuspendedUsers,
+		&stats.Posts,
+		&stats.PostsLastDay,
+		&stats.Comments,
+		&stats.Reactions,
+		&stats.Follows,
+	)
+	if err != nil {
+		return models.Stats{}, err
+	}
+
+	lines, err := repository.db.QueryContext(ctx, "select role, count(*) from users group by role")
+	if err != nil {
+		return models.Stats{}, err
+	}
+
+	defer lines.Close()
+
+	stats.UsersByRole = map[models.Role]uint64{}
+	for lines.Next() {
+		var role models.Role
+		var count uint64
+		if err := lines.Scan(&role, &count); err != nil {
+			return models.Stats{}, err
+		}
+		stats.UsersByRole[role] = count
+	}
+
+	return stats, lines.Err()
+}
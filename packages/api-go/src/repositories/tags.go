@@ -0,0 +1,97 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxTrendingWindow is how long the hourly buckets behind the trending tags
+// are kept, and therefore the widest window Trending can cover.
+const MaxTrendingWindow = 7 * 24 * time.Hour
+
+type TagRepository interface {
+	Record(ctx context.Context, tags []string, at time.Time) error
+	Trending(ctx context.Context, window time.Duration, limit int) ([]models.TrendingTag, error)
+}
+
+// Tags keeps one sorted set per hour counting how many posts used each tag,
+// so trending tags over any window are a union of the last buckets.
+type Tags struct {
+	redis *redis.Client
+}
+
+func NewTagRepository(redis *redis.Client) *Tags {
+	return &Tags{redis}
+}
+
+func trendingBucket(at time.Time) string {
+	return fmt.Sprintf("tags:trending:%d", at.Unix()/3600)
+}
+
+func (repository Tags) Record(ctx context.Context, tags []string, at time.Time) error {
+	if len(tags) == 0 {
+		return nil
+	}
+
+	key := trendingBucket(at)
+
+	pipe := repository.redis.Pipeline()
+	for _, tag := range tags {
+		pipe.ZIncrBy(ctx, key, 1, tag)
+	}
+	pipe.Expire(ctx, key, MaxTrendingWindow+time.Hour)
+	_, err := pipe.Exec(ctx)
+
+	return err
+}
+
+// Trending returns the tags used by the most posts over the last window,
+// rounded up to whole hours. The union of the buckets is cached for a minute.
+func (repository Tags) Trending(ctx context.Context, window time.Duration, limit int) ([]models.TrendingTag, error) {
+	if window > MaxTrendingWindow {
+		window = MaxTrendingWindow
+	}
+	hours := int((window + time.Hour - 1) / time.Hour)
+	if hours < 1 {
+		hours = 1
+	}
+
+	now := time.Now()
+	key := fmt.Sprintf("tags:trending:window:%d:%d", hours, now.Unix()/3600)
+
+	exists, err := repository.redis.Exists(ctx, key).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	if exists == 0 {
+		buckets := make([]string, hours)
+		for i := range buckets {
+			buckets[i] = trendingBucket(now.Add(-time.Duration(i) * time.Hour))
+		}
+
+		pipe := repository.redis.TxPipeline()
+		pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: buckets})
+		pipe.Expire(ctx, key, time.Minute)
+		if _, err := pipe.Exec(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	entries, err := repository.redis.ZRevRangeWithScores(ctx, key, 0, int64(limit-1)).Result()
+	if err != nil {
+		return nil, err
+	}
+
+	trending := make([]models.TrendingTag, 0, len(entries))
+	for _, entry := range entries {
+		tag, _ := entry.Member.(string)
+		trending = append(trending, models.TrendingTag{Tag: tag, Count: uint64(entry.Score)})
+	}
+
+	return trending, nil
+}
@@ -0,0 +1,58 @@
+package repositories_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/go-redis/redismock/v9"
+	"github.com/otaviopontes/api-go/src/models"
+	"github.com/otaviopontes/api-go/src/repositories"
+	goredis "github.com/redis/go-redis/v9"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordTags(t *testing.T) {
+	redis, mock := redismock.NewClientMock()
+
+	tagRepo := repositories.NewTagRepository(redis)
+
+	at := time.Now()
+	bucket := fmt.Sprintf("tags:trending:%d", at.Unix()/3600)
+
+	mock.ExpectZIncrBy(bucket, 1, "golang").SetVal(1)
+	mock.ExpectZIncrBy(bucket, 1, "redis").SetVal(3)
+	mock.ExpectExpire(bucket, repositories.MaxTrendingWindow+time.Hour).SetVal(true)
+
+	err := tagRepo.Record(context.Background(), []string{"golang", "redis"}, at)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTrendingTags(t *testing.T) {
+	redis, mock := redismock.NewClientMock()
+
+	tagRepo := repositories.NewTagRepository(redis)
+
+	now := time.Now()
+	key := fmt.Sprintf("tags:trending:window:2:%d", now.Unix()/3600)
+
+	mock.ExpectExists(key).SetVal(0)
+	mock.ExpectTxPipeline()
+	mock.ExpectZUnionStore(key, &goredis.ZStore{Keys: []string{
+		fmt.Sprintf("tags:trending:%d", now.Unix()/3600),
+		fmt.Sprintf("tags:trending:%d", now.Add(-time.Hour).Unix()/3600),
+	}}).SetVal(2)
+	mock.ExpectExpire(key, time.Minute).SetVal(true)
+	mock.ExpectTxPipelineExec()
+	mock.ExpectZRevRangeWithScores(key, 0, 9).SetVal([]goredis.Z{
+		{Score: 5, Member: "golang"},
+		{Score: 2, Member: "redis"},
+	})
+
+	trending, err := tagRepo.Trending(context.Background(), 2*time.Hour, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.TrendingTag{{Tag: "golang", Count: 5}, {Tag: "redis", Count: 2}}, trending)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+)
+
+// executor is what *sql.DB and *sql.Tx have in common to run queries.
+type executor interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+	PrepareContext(ctx context.Context, query string) (*sql.Stmt, error)
+}
+
+// transaction is the *sql.Tx begun by inTransaction, along with the work to do
+// once it is committed.
+type transaction struct {
+	tx          *sql.Tx
+	afterCommit []func()
+}
+
+type txKey struct{}
+
+// inTransaction runs fn in a transaction on db, committed when fn succeeds and
+// rolled back otherwise. The repository methods fn calls with its ctx join the
+// transaction when they run their queries on conn, and defer their side effects
+// outside of Postgres through afterCommit until it is committed.
+func inTransaction(ctx context.Context, db *sql.DB, fn func(ctx context.Context) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	current := &transaction{tx: tx}
+	if err := fn(context.WithValue(ctx, txKey{}, current)); err != nil {
+		tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, run := range current.afterCommit {
+		run()
+	}
+	return nil
+}
+
+// conn returns the transaction ctx runs in, begun by inTransaction, or db.
+func conn(ctx context.Context, db *sql.DB) executor {
+	if current, ok := ctx.Value(txKey{}).(*transaction); ok {
+		return current.tx
+	}
+	return db
+}
+
+// afterCommit runs fn once the transaction ctx runs in is committed, and never
+// when it is rolled back. Outside of a transaction, fn runs right away.
+func afterCommit(ctx context.Context, fn func()) {
+	if current, ok := ctx.Value(txKey{}).(*transaction); ok {
+		current.afterCommit = append(current.afterCommit, fn)
+		return
+	}
+	fn()
+}
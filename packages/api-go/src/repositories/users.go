@@ -1,24 +1,35 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/google/uuid"
 	_ "github.com/lib/pq"
 	"github.com/otaviopontes/api-go/src/models"
 )
 
+var ErrUserNotFound = errors.New("user not found with this id")
+
 type UserRepository interface {
-	Create(user models.User) error
-	Get(nameOrNick string) ([]models.User, error)
-	GetById(userId uuid.UUID) (models.User, error)
-	Update(userId uuid.UUID, user models.User) error
-	Delete(userId uuid.UUID) error
-	SearchByEmail(email string) (models.User, error)
-	SearchPassword(id uuid.UUID) (string, error)
-	UpdatePassword(userId uuid.UUID, password []byte) error
+	Create(ctx context.Context, user models.User) error
+	Search(ctx context.Context, query string, limit, offset int) ([]models.User, error)
+	List(ctx context.Context, filter models.UserFilter, cursor models.Cursor, limit int) (models.UserPage, error)
+	GetById(ctx context.Context, userId uuid.UUID) (models.User, error)
+	Update(ctx context.Context, userId uuid.UUID, user models.User) error
+	Delete(ctx context.Context, userId uuid.UUID) error
+	SearchByEmail(ctx context.Context, email string) (models.User, error)
+	SearchPassword(ctx context.Context, id uuid.UUID) (string, error)
+	UpdatePassword(ctx context.Context, userId uuid.UUID, password []byte) error
+	Suspend(ctx context.Context, userId uuid.UUID) error
+	Unsuspend(ctx context.Context, userId uuid.UUID) error
+	Follow(ctx context.Context, followerId, followedId uuid.UUID) error
+	Unfollow(ctx context.Context, followerId, followedId uuid.UUID) error
+	GetFollowers(ctx context.Context, userId uuid.UUID) ([]models.User, error)
+	GetFollowing(ctx context.Context, userId uuid.UUID) ([]models.User, error)
 }
 
 type Users struct {
@@ -29,8 +40,8 @@ func NewUserRepository(db *sql.DB) *Users {
 	return &Users{db}
 }
 
-func (repository *Users) Create(user models.User) error {
-	statement, err := repository.db.Prepare(
+func (repository *Users) Create(ctx context.Context, user models.User) error {
+	statement, err := repository.db.PrepareContext(ctx,
 		"INSERT INTO users (name, nick, email, password) VALUES ($1, $2, $3, $4);",
 	)
 	if err != nil {
@@ -38,7 +49,7 @@ func (repository *Users) Create(user models.User) error {
 	}
 	defer statement.Close()
 
-	_, err = statement.Exec(user.Name, user.Nick, user.Email, user.Password)
+	_, err = statement.ExecContext(ctx, user.Name, user.Nick, user.Email, user.Password)
 
 	if err != nil {
 		return err
@@ -47,12 +58,16 @@ func (repository *Users) Create(user models.User) error {
 	return nil
 }
 
-func (repository *Users) Get(nameOrNick string) ([]models.User, error) {
-	nameOrNick = fmt.Sprintf("%%%s%%", nameOrNick) // %nameOrNick%
-
-	lines, err := repository.db.Query(
-		"select id, name, nick, email, createdAt from users where name LIKE ? or nick LIKE ?",
-		nameOrNick, nameOrNick,
+// Search returns the users whose nick or name match query, best matches first.
+// query accepts the web search syntax of websearch_to_tsquery.
+func (repository *Users) Search(ctx context.Context, query string, limit, offset int) ([]models.User, error) {
+	lines, err := repository.db.QueryContext(ctx, `
+	select id, name, nick, email, createdAt
+	from users, websearch_to_tsquery('simple', $1) query
+	where search_vector @@ query
+	order by ts_rank(search_vector, query) desc, nick
+	limit $2 offset $3`,
+		query, limit, offset,
 	)
 	if err != nil {
 		return nil, err
@@ -60,7 +75,7 @@ func (repository *Users) Get(nameOrNick string) ([]models.User, error) {
 
 	defer lines.Close()
 
-	var users []models.User
+	users := []models.User{}
 
 	for lines.Next() {
 		var user models.User
@@ -78,14 +93,97 @@ func (repository *Users) Get(nameOrNick string) ([]models.User, error) {
 		users = append(users, user)
 	}
 
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+
 	return users, nil
 
 }
 
-func (repository *Users) GetById(userId uuid.UUID) (models.User, error) {
+// List returns a page of the users matching filter created before cursor,
+// newest first, with their role and suspension.
+func (repository *Users) List(ctx context.Context, filter models.UserFilter, cursor models.Cursor, limit int) (models.UserPage, error) {
+	args := []interface{}{}
+	query := `
+	select id, name, nick, email, role, suspendedAt, createdAt
+	from users
+	where true`
+	if filter.Query != "" {
+		args = append(args, "%"+escapeLike(filter.Query)+"%")
+		query += fmt.Sprintf(" and (nick ilike $%[1]d or name ilike $%[1]d or email ilike $%[1]d)", len(args))
+	}
+	if filter.Role != "" {
+		args = append(args, filter.Role)
+		query += fmt.Sprintf(" and role = $%d", len(args))
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			query += " and suspendedAt is not null"
+		} else {
+			query += " and suspendedAt is null"
+		}
+	}
+	if !cursor.IsZero() {
+		args = append(args, cursor.CreatedAt, cursor.Id)
+		query += fmt.Sprintf(" and (createdAt, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, limit+1)
+	query += fmt.Sprintf("\n\torder by createdAt desc, id desc\n\tlimit $%d", len(args))
+
+	lines, err := repository.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return models.UserPage{}, err
+	}
+
+	defer lines.Close()
+
+	users := []models.User{}
+	for lines.Next() {
+		var user models.User
+
+		if err = lines.Scan(
+			&user.Id,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.Role,
+			&user.SuspendedAt,
+			&user.CreatedAt,
+		); err != nil {
+			return models.UserPage{}, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := lines.Err(); err != nil {
+		return models.UserPage{}, err
+	}
+
+	page := models.UserPage{Users: users}
+	if len(users) > limit {
+		page.Users = users[:limit]
+		last := page.Users[limit-1]
+		page.NextCursor = models.Cursor{CreatedAt: last.CreatedAt, Id: last.Id}.Encode()
+	}
+
+	return page, nil
+}
+
+// escapeLike escapes the wildcards of a like pattern.
+func escapeLike(value string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value)
+}
+
+func (repository *Users) GetById(ctx context.Context, userId uuid.UUID) (models.User, error) {
 
-	lines, err := repository.db.Query(
-		"select id, name, nick, email, createdAt from users where id = $1",
+	lines, err := repository.db.QueryContext(ctx, `
+	select id, name, nick, email, createdAt,
+	(select count(*) from follows where followed_id = users.id),
+	(select count(*) from follows where follower_id = users.id),
+	role, suspendedAt
+	from users where id = $1`,
 		userId,
 	)
 	if err != nil {
@@ -103,20 +201,24 @@ func (repository *Users) GetById(userId uuid.UUID) (models.User, error) {
 			&user.Nick,
 			&user.Email,
 			&user.CreatedAt,
+			&user.Followers,
+			&user.Following,
+			&user.Role,
+			&user.SuspendedAt,
 		); err != nil {
 			return models.User{}, err
 		}
 
 	} else {
-		return models.User{}, errors.New("user not found with this id")
+		return models.User{}, ErrUserNotFound
 	}
 
 	return user, nil
 
 }
 
-func (repository *Users) Update(userId uuid.UUID, user models.User) error {
-	statement, err := repository.db.Prepare(
+func (repository *Users) Update(ctx context.Context, userId uuid.UUID, user models.User) error {
+	statement, err := repository.db.PrepareContext(ctx,
 		"update users set name = $1, nick = $2, email = $3 where id = $4",
 	)
 	if err != nil {
@@ -125,7 +227,7 @@ func (repository *Users) Update(userId uuid.UUID, user models.User) error {
 
 	defer statement.Close()
 
-	_, err = statement.Exec(user.Name, user.Nick, user.Email, userId)
+	_, err = statement.ExecContext(ctx, user.Name, user.Nick, user.Email, userId)
 	if err != nil {
 		return err
 	}
@@ -133,8 +235,8 @@ func (repository *Users) Update(userId uuid.UUID, user models.User) error {
 	return nil
 }
 
-func (repository *Users) Delete(userId uuid.UUID) error {
-	statement, err := repository.db.Prepare(
+func (repository *Users) Delete(ctx context.Context, userId uuid.UUID) error {
+	statement, err := repository.db.PrepareContext(ctx,
 		"delete from users where id = $1",
 	)
 	if err != nil {
@@ -143,7 +245,7 @@ func (repository *Users) Delete(userId uuid.UUID) error {
 
 	defer statement.Close()
 
-	_, err = statement.Exec(userId)
+	_, err = statement.ExecContext(ctx, userId)
 	if err != nil {
 		return err
 	}
@@ -151,8 +253,8 @@ func (repository *Users) Delete(userId uuid.UUID) error {
 	return nil
 }
 
-func (repository *Users) SearchByEmail(email string) (models.User, error) {
-	line, err := repository.db.Query("select id, password from users where email = $1", email)
+func (repository *Users) SearchByEmail(ctx context.Context, email string) (models.User, error) {
+	line, err := repository.db.QueryContext(ctx, "select id, password, role, suspendedAt from users where email = $1", email)
 	if err != nil {
 		return models.User{}, err
 	}
@@ -163,6 +265,8 @@ func (repository *Users) SearchByEmail(email string) (models.User, error) {
 		err := line.Scan(
 			&user.Id,
 			&user.Password,
+			&user.Role,
+			&user.SuspendedAt,
 		)
 		if err != nil {
 			return models.User{}, err
@@ -174,8 +278,8 @@ func (repository *Users) SearchByEmail(email string) (models.User, error) {
 	return user, nil
 }
 
-func (repository *Users) SearchPassword(id uuid.UUID) (string, error) {
-	line, err := repository.db.Query("select password from users where id = $1", id)
+func (repository *Users) SearchPassword(ctx context.Context, id uuid.UUID) (string, error) {
+	line, err := repository.db.QueryContext(ctx, "select password from users where id = $1", id)
 	if err != nil {
 		return "", err
 	}
@@ -196,8 +300,8 @@ func (repository *Users) SearchPassword(id uuid.UUID) (string, error) {
 	return password, nil
 }
 
-func (repository *Users) UpdatePassword(userId uuid.UUID, password []byte) error {
-	statement, err := repository.db.Prepare(
+func (repository *Users) UpdatePassword(ctx context.Context, userId uuid.UUID, password []byte) error {
+	statement, err := conn(ctx, repository.db).PrepareContext(ctx,
 		"update users set password = $1 where id = $2",
 	)
 	if err != nil {
@@ -206,10 +310,133 @@ func (repository *Users) UpdatePassword(userId uuid.UUID, password []byte) error
 
 	defer statement.Close()
 
-	_, err = statement.Exec(password, userId)
+	_, err = statement.ExecContext(ctx, password, userId)
 	if err != nil {
 		return err
 	}
 
 	return nil
 }
+
+// Suspend keeps userId from signing in until Unsuspend is called. Suspending
+// a user again keeps the time they were first suspended at.
+func (repository *Users) Suspend(ctx context.Context, userId uuid.UUID) error {
+	return repository.setSuspended(ctx,
+		"update users set suspendedAt = coalesce(suspendedAt, CURRENT_TIMESTAMP) where id = $1",
+		userId,
+	)
+}
+
+func (repository *Users) Unsuspend(ctx context.Context, userId uuid.UUID) error {
+	return repository.setSuspended(ctx,
+		"update users set suspendedAt = null where id = $1",
+		userId,
+	)
+}
+
+func (repository *Users) setSuspended(ctx context.Context, query string, userId uuid.UUID) error {
+	result, err := conn(ctx, repository.db).ExecContext(ctx, query, userId)
+	if err != nil {
+		return err
+	}
+
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if changed == 0 {
+		return ErrUserNotFound
+	}
+	return nil
+}
+
+func (repository *Users) Follow(ctx context.Context, followerId, followedId uuid.UUID) error {
+	statement, err := repository.db.PrepareContext(ctx,
+		"insert into follows (follower_id, followed_id) values ($1, $2) on conflict do nothing",
+	)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(ctx, followerId, followedId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *Users) Unfollow(ctx context.Context, followerId, followedId uuid.UUID) error {
+	statement, err := repository.db.PrepareContext(ctx,
+		"delete from follows where follower_id = $1 and followed_id = $2",
+	)
+	if err != nil {
+		return err
+	}
+
+	defer statement.Close()
+
+	_, err = statement.ExecContext(ctx, followerId, followedId)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (repository *Users) GetFollowers(ctx context.Context, userId uuid.UUID) ([]models.User, error) {
+	return repository.listFollows(ctx, `
+	select u.id, u.name, u.nick, u.email, u.createdAt
+	from users u
+	join follows f on f.follower_id = u.id
+	where f.followed_id = $1
+	order by f.createdAt desc`,
+		userId,
+	)
+}
+
+func (repository *Users) GetFollowing(ctx context.Context, userId uuid.UUID) ([]models.User, error) {
+	return repository.listFollows(ctx, `
+	select u.id, u.name, u.nick, u.email, u.createdAt
+	from users u
+	join follows f on f.followed_id = u.id
+	where f.follower_id = $1
+	order by f.createdAt desc`,
+		userId,
+	)
+}
+
+func (repository *Users) listFollows(ctx context.Context, query string, userId uuid.UUID) ([]models.User, error) {
+	lines, err := repository.db.QueryContext(ctx, query, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	defer lines.Close()
+
+	users := []models.User{}
+
+	for lines.Next() {
+		var user models.User
+
+		if err = lines.Scan(
+			&user.Id,
+			&user.Name,
+			&user.Nick,
+			&user.Email,
+			&user.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
+	}
+
+	if err := lines.Err(); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
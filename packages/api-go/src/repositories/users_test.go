@@ -1,6 +1,8 @@
 package repositories_test
 
 import (
+	"context"
+	"errors"
 	"testing"
 	"time"
 
@@ -30,7 +32,7 @@ func TestCreateUser(t *testing.T) {
 		WithArgs(user.Name, user.Nick, user.Email, user.Password).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = userRepo.Create(user)
+	err = userRepo.Create(context.Background(), user)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -43,19 +45,23 @@ func TestGetUserById(t *testing.T) {
 	userRepo := repositories.NewUserRepository(db)
 	userId := uuid.New()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "nick", "email", "createdAt"}).
-		AddRow(userId, "John Doe", "johnd", "john@example.com", time.Now())
+	rows := sqlmock.NewRows([]string{"id", "name", "nick", "email", "createdAt", "followers", "following", "role", "suspendedAt"}).
+		AddRow(userId, "John Doe", "johnd", "john@example.com", time.Now(), 3, 5, "moderator", nil)
 
-	mock.ExpectQuery("select id, name, nick, email, createdAt from users where id =").
+	mock.ExpectQuery("select id, name, nick, email, createdAt, .* from users where id =").
 		WithArgs(userId).
 		WillReturnRows(rows)
 
-	user, err := userRepo.GetById(userId)
+	user, err := userRepo.GetById(context.Background(), userId)
 	assert.NoError(t, err)
 	assert.Equal(t, userId, user.Id)
 	assert.Equal(t, "John Doe", user.Name)
 	assert.Equal(t, "johnd", user.Nick)
 	assert.Equal(t, "john@example.com", user.Email)
+	assert.Equal(t, uint64(3), user.Followers)
+	assert.Equal(t, uint64(5), user.Following)
+	assert.Equal(t, models.RoleModerator, user.Role)
+	assert.Nil(t, user.SuspendedAt)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -70,17 +76,20 @@ func TestSearchByEmail(t *testing.T) {
 	userId := uuid.New()
 	password := "hashedpassword"
 
-	rows := sqlmock.NewRows([]string{"id", "password"}).
-		AddRow(userId, password)
+	suspendedAt := time.Now()
+	rows := sqlmock.NewRows([]string{"id", "password", "role", "suspendedAt"}).
+		AddRow(userId, password, "user", suspendedAt)
 
-	mock.ExpectQuery("select id, password from users where email =").
+	mock.ExpectQuery("select id, password, role, suspendedAt from users where email =").
 		WithArgs(email).
 		WillReturnRows(rows)
 
-	user, err := userRepo.SearchByEmail(email)
+	user, err := userRepo.SearchByEmail(context.Background(), email)
 	assert.NoError(t, err)
 	assert.Equal(t, userId, user.Id)
 	assert.Equal(t, password, user.Password)
+	assert.Equal(t, models.RoleUser, user.Role)
+	assert.Equal(t, suspendedAt, *user.SuspendedAt)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -103,7 +112,7 @@ func TestUpdateUser(t *testing.T) {
 		WithArgs(user.Name, user.Nick, user.Email, userId).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = userRepo.Update(userId, user)
+	err = userRepo.Update(context.Background(), userId, user)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -122,7 +131,7 @@ func TestDeleteUser(t *testing.T) {
 		WithArgs(userId).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = userRepo.Delete(userId)
+	err = userRepo.Delete(context.Background(), userId)
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -142,7 +151,159 @@ func TestUpdatePassword(t *testing.T) {
 		WithArgs(password, userId).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	err = userRepo.UpdatePassword(userId, password)
+	err = userRepo.UpdatePassword(context.Background(), userId, password)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFollow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	followerId := uuid.New()
+	followedId := uuid.New()
+
+	mock.ExpectPrepare("insert into follows").
+		ExpectExec().
+		WithArgs(followerId, followedId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = userRepo.Follow(context.Background(), followerId, followedId)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUnfollow(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	followerId := uuid.New()
+	followedId := uuid.New()
+
+	mock.ExpectPrepare("delete from follows where follower_id =").
+		ExpectExec().
+		WithArgs(followerId, followedId).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+
+	err = userRepo.Unfollow(context.Background(), followerId, followedId)
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	userId := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "nick", "email", "createdAt"}).
+		AddRow(uuid.New(), "Jane Doe", "janed", "jane@example.com", time.Now())
+
+	mock.ExpectQuery(`join follows f on f.follower_id = u.id\s+where f.followed_id = \$1`).
+		WithArgs(userId).
+		WillReturnRows(rows)
+
+	followers, err := userRepo.GetFollowers(context.Background(), userId)
+	assert.NoError(t, err)
+	assert.Len(t, followers, 1)
+	assert.Equal(t, "janed", followers[0].Nick)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetFollowersIterationError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	userId := uuid.New()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "nick", "email", "createdAt"}).
+		AddRow(uuid.New(), "Jane Doe", "janed", "jane@example.com", time.Now()).
+		AddRow(uuid.New(), "John Doe", "johnd", "john@example.com", time.Now()).
+		RowError(1, errors.New("connection reset"))
+
+	mock.ExpectQuery(`join follows f on f.follower_id = u.id\s+where f.followed_id = \$1`).
+		WithArgs(userId).
+		WillReturnRows(rows)
+
+	followers, err := userRepo.GetFollowers(context.Background(), userId)
+	assert.Error(t, err)
+	assert.Nil(t, followers)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "nick", "email", "createdAt"}).
+		AddRow(uuid.New(), "John Doe", "johnd", "john@example.com", time.Now())
+
+	mock.ExpectQuery(`websearch_to_tsquery\('simple', \$1\) query\s+where search_vector @@ query`).
+		WithArgs("john", 10, 20).
+		WillReturnRows(rows)
+
+	users, err := userRepo.Search(context.Background(), "john", 10, 20)
+	assert.NoError(t, err)
+	assert.Len(t, users, 1)
+	assert.Equal(t, "johnd", users[0].Nick)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSuspendUnknownUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+	userId := uuid.New()
+
+	mock.ExpectExec(`update users set suspendedAt = coalesce\(suspendedAt, CURRENT_TIMESTAMP\) where id = \$1`).
+		WithArgs(userId).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = userRepo.Suspend(context.Background(), userId)
+	assert.ErrorIs(t, err, repositories.ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestListUsers(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	assert.NoError(t, err)
+	defer db.Close()
+
+	userRepo := repositories.NewUserRepository(db)
+
+	suspended := true
+	first := uuid.New()
+	second := uuid.New()
+	now := time.Now()
+
+	mock.ExpectQuery(`ilike \$1.* and role = \$2 and suspendedAt is not null\s+order by createdAt desc, id desc\s+limit \$3`).
+		WithArgs("%50\\%%", models.RoleUser, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "nick", "email", "role", "suspendedAt", "createdAt"}).
+			AddRow(first, "First", "first", "first@example.com", "user", now, now).
+			AddRow(second, "Second", "second", "second@example.com", "user", now, now.Add(-time.Minute)))
+
+	page, err := userRepo.List(context.Background(), models.UserFilter{Query: "50%", Role: models.RoleUser, Suspended: &suspended}, models.Cursor{}, 1)
+
 	assert.NoError(t, err)
+	assert.Len(t, page.Users, 1)
+	assert.Equal(t, first, page.Users[0].Id)
+	assert.Equal(t, models.Cursor{CreatedAt: now, Id: first}.Encode(), page.NextCursor)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
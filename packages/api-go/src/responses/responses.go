@@ -1,9 +1,13 @@
 package responses
 
 import (
+	"context"
 	"encoding/json"
-	"log"
+	"errors"
+	"log/slog"
 	"net/http"
+
+	"github.com/otaviopontes/api-go/src/logging"
 )
 
 func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
@@ -13,7 +17,8 @@ func JSON(w http.ResponseWriter, statusCode int, data interface{}) {
 
 	if data != nil {
 		if err := json.NewEncoder(w).Encode(data); err != nil {
-			log.Fatal(err)
+			// The status is already sent, all that is left is to report it.
+			slog.Error("encoding response failed", slog.String("error", err.Error()))
 		}
 	}
 }
@@ -27,11 +32,35 @@ func Error(w http.ResponseWriter, statusCode int, err error) {
 	})
 }
 
+var (
+	errTimedOut = errors.New("the request timed out")
+	errCanceled = errors.New("the request was canceled")
+)
+
+// ServerError answers a request that failed on the server side. A failure
+// caused by the request context is reported as 504 when its deadline expired
+// and as 503 when it was canceled, instead of as the driver error behind it.
+func ServerError(w http.ResponseWriter, r *http.Request, err error) {
+	logging.SetError(r.Context(), err)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded), errors.Is(r.Context().Err(), context.DeadlineExceeded):
+		Error(w, http.StatusGatewayTimeout, errTimedOut)
+	case errors.Is(err, context.Canceled), errors.Is(r.Context().Err(), context.Canceled):
+		Error(w, http.StatusServiceUnavailable, errCanceled)
+	default:
+		Error(w, http.StatusInternalServerError, err)
+	}
+}
+
 type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
 type AuthResponse struct {
-	Id    string `json:"id"`
-	Token string `json:"token"`
+	Id           string `json:"id"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	// ExpiresIn is the number of seconds the access token is valid for.
+	ExpiresIn int `json:"expiresIn"`
 }
@@ -1,13 +1,29 @@
 package router
 
 import (
+	"net/http"
+
 	"github.com/gorilla/mux"
+	"github.com/otaviopontes/api-go/src/controllers"
 	"github.com/otaviopontes/api-go/src/router/routes"
+	"github.com/otaviopontes/api-go/src/tracing"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
 )
 
-func Generate() *mux.Router {
+// untraced lists the paths polled by the infrastructure, whose spans would
+// only drown the traces of actual requests.
+var untraced = map[string]bool{
+	"/healthz": true,
+	"/readyz":  true,
+	"/metrics": true,
+}
+
+func Generate(handlers controllers.Handlers) *mux.Router {
 	r := mux.NewRouter()
+	r.Use(otelmux.Middleware(tracing.ServiceName, otelmux.WithFilter(func(r *http.Request) bool {
+		return !untraced[r.URL.Path]
+	})))
 
-	return routes.Configure(r)
+	return routes.Configure(r, handlers)
 
 }
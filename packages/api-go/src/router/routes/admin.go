@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
+)
+
+func adminRoutes(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:         "/api/admin/users",
+			Method:      http.MethodGet,
+			Function:    handlers.Admin.ListUsers,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/users/{id}/suspension",
+			Method:      http.MethodPost,
+			Function:    handlers.Admin.SuspendUser,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/users/{id}/suspension",
+			Method:      http.MethodDelete,
+			Function:    handlers.Admin.UnsuspendUser,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/users/{id}/logout",
+			Method:      http.MethodPost,
+			Function:    handlers.Admin.LogoutUser,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/users/{id}/password-reset",
+			Method:      http.MethodPost,
+			Function:    handlers.Admin.ResetPassword,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/posts/{id}",
+			Method:      http.MethodDelete,
+			Function:    handlers.Admin.DeletePost,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/stats",
+			Method:      http.MethodGet,
+			Function:    handlers.Admin.GetStats,
+			RequireRole: models.RoleAdmin,
+		},
+		{
+			Uri:         "/api/admin/audit",
+			Method:      http.MethodGet,
+			Function:    handlers.Admin.GetAuditLog,
+			RequireRole: models.RoleAdmin,
+		},
+	}
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func authRoutes(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/login",
+			Method:                http.MethodPost,
+			Function:              handlers.Auth.Login,
+			RequireAuthentication: false,
+		},
+		{
+			Uri:                   "/api/auth/refresh",
+			Method:                http.MethodPost,
+			Function:              handlers.Auth.Refresh,
+			RequireAuthentication: false,
+		},
+		{
+			Uri:                   "/api/auth/logout",
+			Method:                http.MethodPost,
+			Function:              handlers.Auth.Logout,
+			RequireAuthentication: true,
+		},
+	}
+}
@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func routesComments(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/posts/{id}/comments",
+			Method:                http.MethodPost,
+			Function:              handlers.Comments.CreateComment,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/comments",
+			Method:                http.MethodGet,
+			Function:              handlers.Comments.GetComments,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/comments/{commentId}",
+			Method:                http.MethodPut,
+			Function:              handlers.Comments.UpdateComment,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/comments/{commentId}",
+			Method:                http.MethodDelete,
+			Function:              handlers.Comments.DeleteComment,
+			RequireAuthentication: true,
+		},
+	}
+}
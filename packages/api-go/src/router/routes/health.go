@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func healthRoutes(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/healthz",
+			Method:                http.MethodGet,
+			Function:              handlers.Health.Live,
+			RequireAuthentication: false,
+		},
+		{
+			Uri:                   "/readyz",
+			Method:                http.MethodGet,
+			Function:              handlers.Health.Ready,
+			RequireAuthentication: false,
+		},
+	}
+}
@@ -0,0 +1,18 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func keyRoutes(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/.well-known/jwks.json",
+			Method:                http.MethodGet,
+			Function:              handlers.Keys.JWKS,
+			RequireAuthentication: false,
+		},
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func routesNotifications(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/notifications",
+			Method:                http.MethodGet,
+			Function:              handlers.Notifications.GetNotifications,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/notifications/read",
+			Method:                http.MethodPost,
+			Function:              handlers.Notifications.ReadNotifications,
+			RequireAuthentication: true,
+		},
+	}
+}
@@ -6,48 +6,68 @@ import (
 	"github.com/otaviopontes/api-go/src/controllers"
 )
 
-var routesPosts = []Route{
-	{
-		Uri:                   "/api/posts",
-		Method:                http.MethodPost,
-		Function:              controllers.CreatePost,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/posts",
-		Method:                http.MethodGet,
-		Function:              controllers.GetPosts,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/posts/{id}",
-		Method:                http.MethodGet,
-		Function:              controllers.GetPost,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/posts/{id}",
-		Method:                http.MethodPut,
-		Function:              controllers.UpdatePost,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/posts/{id}",
-		Method:                http.MethodDelete,
-		Function:              controllers.DeletePost,
-		RequireAuthentication: true,
-	},
+func routesPosts(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/posts",
+			Method:                http.MethodPost,
+			Function:              handlers.Posts.CreatePost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts",
+			Method:                http.MethodGet,
+			Function:              handlers.Posts.GetPosts,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}",
+			Method:                http.MethodGet,
+			Function:              handlers.Posts.GetPost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}",
+			Method:                http.MethodPut,
+			Function:              handlers.Posts.UpdatePost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}",
+			Method:                http.MethodDelete,
+			Function:              handlers.Posts.DeletePost,
+			RequireAuthentication: true,
+		},
 
-	{
-		Uri:                   "/api/posts/{id}/like",
-		Method:                http.MethodPost,
-		Function:              controllers.LikePost,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/posts/{id}/dislike",
-		Method:                http.MethodPost,
-		Function:              controllers.DislikePost,
-		RequireAuthentication: true,
-	},
+		{
+			Uri:                   "/api/posts/{id}/like",
+			Method:                http.MethodPost,
+			Function:              handlers.Posts.LikePost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/dislike",
+			Method:                http.MethodPost,
+			Function:              handlers.Posts.DislikePost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/reactions/{type}",
+			Method:                http.MethodPut,
+			Function:              handlers.Posts.ReactToPost,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/posts/{id}/reactions/{type}",
+			Method:                http.MethodDelete,
+			Function:              handlers.Posts.RemovePostReaction,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/timeline",
+			Method:                http.MethodGet,
+			Function:              handlers.Posts.GetTimeline,
+			RequireAuthentication: true,
+		},
+	}
 }
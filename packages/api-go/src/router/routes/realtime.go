@@ -0,0 +1,26 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+// routesRealtime must be registered before routesPosts, whose /api/posts/{id}
+// would otherwise match /api/posts/stream.
+var routesRealtime = []Route{
+	{
+		Uri:                   "/api/ws",
+		Method:                http.MethodGet,
+		Function:              controllers.SubscribeEvents,
+		RequireAuthentication: true,
+		Streaming:             true,
+	},
+	{
+		Uri:                   "/api/posts/stream",
+		Method:                http.MethodGet,
+		Function:              controllers.StreamEvents,
+		RequireAuthentication: true,
+		Streaming:             true,
+	},
+}
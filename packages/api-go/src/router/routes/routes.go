@@ -5,7 +5,10 @@ import (
 
 	"github.com/gorilla/mux"
 	_ "github.com/otaviopontes/api-go/docs"
+	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/metrics"
 	middlewares "github.com/otaviopontes/api-go/src/middleware"
+	"github.com/otaviopontes/api-go/src/models"
 	httpSwagger "github.com/swaggo/http-swagger"
 )
 
@@ -14,22 +17,43 @@ type Route struct {
 	Method                string
 	Function              func(http.ResponseWriter, *http.Request)
 	RequireAuthentication bool
+	// RequireRole, when set, limits the route to the users whose role
+	// includes it. It implies RequireAuthentication.
+	RequireRole models.Role
+	// Streaming routes hold the connection open for as long as the client
+	// listens, so they are not bound by the request timeout.
+	Streaming bool
 }
 
-func Configure(r *mux.Router) *mux.Router {
+func Configure(r *mux.Router, handlers controllers.Handlers) *mux.Router {
 
-	routes := userRoutes
-	routes = append(routes, loginRoute)
-	routes = append(routes, routesPosts...)
+	routes := userRoutes(handlers)
+	routes = append(routes, authRoutes(handlers)...)
+	routes = append(routes, searchRoute(handlers))
+	routes = append(routes, routesRealtime...)
+	routes = append(routes, routesPosts(handlers)...)
+	routes = append(routes, routesComments(handlers)...)
+	routes = append(routes, routesTags(handlers)...)
+	routes = append(routes, routesNotifications(handlers)...)
+	routes = append(routes, healthRoutes(handlers)...)
+	routes = append(routes, keyRoutes(handlers)...)
+	routes = append(routes, adminRoutes(handlers)...)
 
 	for _, route := range routes {
-		if route.RequireAuthentication {
-			r.HandleFunc(route.Uri, middlewares.Logger(middlewares.Authenticate(route.Function))).Methods(route.Method)
-		} else {
-
-			r.HandleFunc(route.Uri, middlewares.Logger(route.Function)).Methods(route.Method)
+		handler := route.Function
+		if route.RequireRole != "" {
+			handler = middlewares.RequireRole(route.RequireRole, handler)
+		}
+		if route.RequireAuthentication || route.RequireRole != "" {
+			handler = middlewares.Authenticate(handlers.Tokens, handlers.Sessions, handler)
 		}
+		if !route.Streaming {
+			handler = middlewares.Timeout(handler)
+		}
+
+		r.HandleFunc(route.Uri, middlewares.Logger(middlewares.Metrics(route.Uri, handler))).Methods(route.Method)
 	}
+	r.Handle("/metrics", metrics.Handler()).Methods(http.MethodGet)
 	r.PathPrefix("/swagger/").Handler(httpSwagger.WrapHandler)
 
 	return r
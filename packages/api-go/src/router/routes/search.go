@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func searchRoute(handlers controllers.Handlers) Route {
+	return Route{
+		Uri:                   "/api/search",
+		Method:                http.MethodGet,
+		Function:              handlers.Search.Search,
+		RequireAuthentication: true,
+	}
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"net/http"
+
+	"github.com/otaviopontes/api-go/src/controllers"
+)
+
+func routesTags(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/tags/trending",
+			Method:                http.MethodGet,
+			Function:              handlers.Tags.GetTrendingTags,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/tags/{tag}/posts",
+			Method:                http.MethodGet,
+			Function:              handlers.Tags.GetTagPosts,
+			RequireAuthentication: true,
+		},
+	}
+}
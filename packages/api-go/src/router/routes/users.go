@@ -4,38 +4,77 @@ import (
 	"net/http"
 
 	"github.com/otaviopontes/api-go/src/controllers"
+	"github.com/otaviopontes/api-go/src/models"
 )
 
-var userRoutes = []Route{
-	{
-		Uri:                   "/api/users",
-		Method:                http.MethodPost,
-		Function:              controllers.CreateUser,
-		RequireAuthentication: false,
-	},
+func userRoutes(handlers controllers.Handlers) []Route {
+	return []Route{
+		{
+			Uri:                   "/api/users",
+			Method:                http.MethodPost,
+			Function:              handlers.Users.CreateUser,
+			RequireAuthentication: false,
+		},
 
-	{
-		Uri:                   "/api/users/{id}",
-		Method:                http.MethodGet,
-		Function:              controllers.GetUser,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/users/{id}",
-		Method:                http.MethodPut,
-		Function:              controllers.UpdateUser,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/users/{id}",
-		Method:                http.MethodDelete,
-		Function:              controllers.DeleteUser,
-		RequireAuthentication: true,
-	},
-	{
-		Uri:                   "/api/users/{id}/update-password",
-		Method:                http.MethodPost,
-		Function:              controllers.UpdatePassword,
-		RequireAuthentication: true,
-	},
+		{
+			Uri:                   "/api/users/{id}",
+			Method:                http.MethodGet,
+			Function:              handlers.Users.GetUser,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}",
+			Method:                http.MethodPut,
+			Function:              handlers.Users.UpdateUser,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}",
+			Method:                http.MethodDelete,
+			Function:              handlers.Users.DeleteUser,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}/update-password",
+			Method:                http.MethodPost,
+			Function:              handlers.Users.UpdatePassword,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}/follow",
+			Method:                http.MethodPost,
+			Function:              handlers.Users.FollowUser,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}/follow",
+			Method:                http.MethodDelete,
+			Function:              handlers.Users.UnfollowUser,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:         "/api/users/{id}/suspension",
+			Method:      http.MethodPost,
+			Function:    handlers.Admin.SuspendUser,
+			RequireRole: models.RoleModerator,
+		},
+		{
+			Uri:         "/api/users/{id}/suspension",
+			Method:      http.MethodDelete,
+			Function:    handlers.Admin.UnsuspendUser,
+			RequireRole: models.RoleModerator,
+		},
+		{
+			Uri:                   "/api/users/{id}/followers",
+			Method:                http.MethodGet,
+			Function:              handlers.Users.GetFollowers,
+			RequireAuthentication: true,
+		},
+		{
+			Uri:                   "/api/users/{id}/following",
+			Method:                http.MethodGet,
+			Function:              handlers.Users.GetFollowing,
+			RequireAuthentication: true,
+		},
+	}
 }
@@ -0,0 +1,302 @@
+package timelines
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/otaviopontes/api-go/src/metrics"
+	"github.com/redis/go-redis/v9"
+)
+
+// MaxEntries caps every timeline sorted set. Older posts are served from SQL.
+var MaxEntries int64 = 800
+
+const (
+	// queueSize bounds the channel feeding the workers. When it is full the
+	// job is dropped, so a burst of writes never blocks the request goroutines.
+	queueSize = 1024
+	// batchSize is the number of followers written per Redis pipeline.
+	batchSize = 500
+	// pendingTTL bounds how long a rebuild collects pushes, so the pending key
+	// of a rebuild that failed midway does not outlive it.
+	pendingTTL = time.Minute
+	// keyTTL lets timelines of inactive users expire; they are rebuilt from
+	// SQL on their next read. Rebuilds and pushes both refresh it.
+	keyTTL = 48 * time.Hour
+)
+
+// Marker is the member every rebuilt timeline holds, with a score of 0 so it
+// sorts before any post. It keeps the timeline of a user who follows nobody, or
+// only silent accounts, warm instead of rebuilding it on every read. Readers
+// skip it by only ranging over scores above 0.
+const Marker = "warm"
+
+// pushScript adds a post to a timeline only when the key is already warm, so a
+// cold timeline never ends up holding just the newest posts, trims it to the
+// newest ARGV[3] entries and keeps it alive for ARGV[4] more seconds. While the
+// timeline is being rebuilt, the post is also kept in KEYS[2] for the rebuild
+// to merge in, since the rebuild may have read the posts before this one.
+var pushScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[2]) == 1 then
+	redis.call('ZADD', KEYS[2], ARGV[1], ARGV[2])
+end
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	redis.call('ZADD', KEYS[1], ARGV[1], ARGV[2])
+	redis.call('ZREMRANGEBYRANK', KEYS[1], 0, -(tonumber(ARGV[3]) + 1))
+	redis.call('EXPIRE', KEYS[1], ARGV[4])
+end
+return 0
+`)
+
+type jobKind int
+
+const (
+	pushPost jobKind = iota
+	removePost
+	rebuildTimeline
+)
+
+func (kind jobKind) String() string {
+	switch kind {
+	case pushPost:
+		return "push"
+	case removePost:
+		return "remove"
+	default:
+		return "rebuild"
+	}
+}
+
+type job struct {
+	kind      jobKind
+	postId    uuid.UUID
+	userId    uuid.UUID
+	createdAt time.Time
+}
+
+type pool struct {
+	db    *sql.DB
+	redis *redis.Client
+	jobs  chan job
+	wg    sync.WaitGroup
+}
+
+var (
+	mutex   sync.RWMutex
+	workers *pool
+)
+
+// Key returns the Redis sorted set holding the timeline of userId.
+func Key(userId uuid.UUID) string {
+	return fmt.Sprintf("timeline:%s", userId)
+}
+
+// pendingKey returns the sorted set collecting the posts pushed to the
+// timeline of userId while it is being rebuilt.
+func pendingKey(userId uuid.UUID) string {
+	return fmt.Sprintf("timeline:%s:pending", userId)
+}
+
+// Score converts a post creation time into its sorted set score. Microseconds
+// match the precision of Postgres timestamps and fit exactly in a float64.
+func Score(createdAt time.Time) float64 {
+	return float64(createdAt.UnixMicro())
+}
+
+// Start launches the fan-out workers. Until it is called, and after Stop,
+// Push, Remove and Rebuild are no-ops.
+func Start(db *sql.DB, redis *redis.Client, count int) {
+	mutex.Lock()
+	defer mutex.Unlock()
+
+	workers = &pool{db: db, redis: redis, jobs: make(chan job, queueSize)}
+	for i := 0; i < count; i++ {
+		workers.wg.Add(1)
+		go workers.run()
+	}
+}
+
+// Stop closes the queue and waits for the pending jobs to finish.
+func Stop() {
+	mutex.Lock()
+	current := workers
+	workers = nil
+	mutex.Unlock()
+
+	if current == nil {
+		return
+	}
+
+	close(current.jobs)
+	current.wg.Wait()
+}
+
+// Push adds a new post to the timelines of its author's followers.
+func Push(postId, authorId uuid.UUID, createdAt time.Time) {
+	enqueue(job{kind: pushPost, postId: postId, userId: authorId, createdAt: createdAt})
+}
+
+// Remove deletes a post from the timelines of its author's followers.
+func Remove(postId, authorId uuid.UUID) {
+	enqueue(job{kind: removePost, postId: postId, userId: authorId})
+}
+
+// Rebuild reloads the timeline of userId from SQL, e.g. after it went cold or
+// the user followed or unfollowed someone.
+func Rebuild(userId uuid.UUID) {
+	enqueue(job{kind: rebuildTimeline, userId: userId})
+}
+
+func enqueue(j job) {
+	mutex.RLock()
+	defer mutex.RUnlock()
+
+	if workers == nil {
+		return
+	}
+
+	select {
+	case workers.jobs <- j:
+	default:
+		// A dropped push or remove leaves the timelines of the followers off
+		// by one post until they expire; a dropped rebuild is retried by the
+		// next read of the cold timeline.
+		metrics.TimelineJobsDropped.WithLabelValues(j.kind.String()).Inc()
+		slog.Warn("timeline job dropped, queue full", slog.String("kind", j.kind.String()), slog.String("user_id", j.userId.String()))
+	}
+}
+
+func (p *pool) run() {
+	defer p.wg.Done()
+
+	for j := range p.jobs {
+		p.process(j)
+	}
+}
+
+func (p *pool) process(j job) {
+	var err error
+
+	switch j.kind {
+	case pushPost:
+		err = p.push(j)
+	case removePost:
+		err = p.remove(j)
+	case rebuildTimeline:
+		err = p.rebuild(j.userId)
+	}
+
+	if err != nil {
+		slog.Error("timeline fan-out failed", slog.String("user_id", j.userId.String()), slog.String("error", err.Error()))
+	}
+}
+
+func (p *pool) push(j job) error {
+	score := Score(j.createdAt)
+
+	return p.eachFollower(j.userId, func(pipe redis.Pipeliner, followerId uuid.UUID) {
+		pushScript.Eval(context.Background(), pipe, []string{Key(followerId), pendingKey(followerId)}, score, j.postId.String(), MaxEntries, int64(keyTTL.Seconds()))
+	})
+}
+
+func (p *pool) remove(j job) error {
+	return p.eachFollower(j.userId, func(pipe redis.Pipeliner, followerId uuid.UUID) {
+		pipe.ZRem(context.Background(), Key(followerId), j.postId.String())
+	})
+}
+
+// eachFollower queues one command per follower of authorId, flushing the
+// pipeline every batchSize followers.
+func (p *pool) eachFollower(authorId uuid.UUID, queue func(redis.Pipeliner, uuid.UUID)) error {
+	lines, err := p.db.Query("select follower_id from follows where followed_id = $1", authorId)
+	if err != nil {
+		return err
+	}
+
+	defer lines.Close()
+
+	ctx := context.Background()
+	pipe := p.redis.Pipeline()
+
+	for lines.Next() {
+		var followerId uuid.UUID
+		if err := lines.Scan(&followerId); err != nil {
+			return err
+		}
+
+		queue(pipe, followerId)
+
+		if pipe.Len() >= batchSize {
+			if _, err := pipe.Exec(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// rebuild replaces the timeline of userId with the newest posts of the users
+// they follow. The posts pushed while it reads them are collected in the
+// pending key and merged in, so none goes missing whether the timeline was
+// cold, which push skips, or warm, which the replacement overwrites.
+func (p *pool) rebuild(userId uuid.UUID) error {
+	ctx := context.Background()
+	key, pending := Key(userId), pendingKey(userId)
+
+	pipe := p.redis.TxPipeline()
+	pipe.ZAdd(ctx, pending, redis.Z{Score: 0, Member: Marker})
+	pipe.Expire(ctx, pending, pendingTTL)
+	if _, err := pipe.Exec(ctx); err != nil {
+		return err
+	}
+
+	lines, err := p.db.Query(`
+	select id, createdat from posts
+	where author_id in (select followed_id from follows where follower_id = $1)
+	order by createdat desc, id desc
+	limit $2`,
+		userId, MaxEntries,
+	)
+	if err != nil {
+		return err
+	}
+
+	defer lines.Close()
+
+	entries := []redis.Z{{Score: 0, Member: Marker}}
+	for lines.Next() {
+		var postId uuid.UUID
+		var createdAt time.Time
+		if err := lines.Scan(&postId, &createdAt); err != nil {
+			return err
+		}
+		entries = append(entries, redis.Z{Score: Score(createdAt), Member: postId.String()})
+	}
+	if err := lines.Err(); err != nil {
+		return err
+	}
+
+	pipe = p.redis.TxPipeline()
+	pipe.Del(ctx, key)
+	pipe.ZAdd(ctx, key, entries...)
+	pipe.ZUnionStore(ctx, key, &redis.ZStore{Keys: []string{key, pending}, Aggregate: "MAX"})
+	pipe.Del(ctx, pending)
+	pipe.ZRemRangeByRank(ctx, key, 0, -(MaxEntries + 1))
+	pipe.Expire(ctx, key, keyTTL)
+	_, err = pipe.Exec(ctx)
+
+	return err
+}
@@ -0,0 +1,114 @@
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.24.0"
+)
+
+// ServiceName identifies the spans of the API and names its tracer.
+const ServiceName = "api-go"
+
+const (
+	ExporterNone   = "none"
+	ExporterOTLP   = "otlp"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+)
+
+// Options selects where the spans go. Endpoint is the OTLP/HTTP collector
+// address, e.g. localhost:4318, and File the path the file exporter appends
+// to. Spans of requests without a sampled parent are kept with SampleRatio.
+type Options struct {
+	Exporter    string
+	Endpoint    string
+	Insecure    bool
+	File        string
+	SampleRatio float64
+}
+
+// Setup installs the W3C trace context propagator, so traceparent headers are
+// honored, and a tracer provider exporting to options.Exporter. With
+// ExporterNone spans are not recorded. The returned function flushes the
+// pending spans and must be called before exiting.
+func Setup(ctx context.Context, options Options) (func(context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	var exporter sdktrace.SpanExporter
+	var closeFile func() error
+
+	switch options.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+
+	case ExporterOTLP:
+		clientOptions := []otlptracehttp.Option{}
+		if options.Endpoint != "" {
+			clientOptions = append(clientOptions, otlptracehttp.WithEndpoint(options.Endpoint))
+		}
+		if options.Insecure {
+			clientOptions = append(clientOptions, otlptracehttp.WithInsecure())
+		}
+
+		var err error
+		if exporter, err = otlptracehttp.New(ctx, clientOptions...); err != nil {
+			return nil, err
+		}
+
+	case ExporterStdout:
+		var err error
+		if exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint()); err != nil {
+			return nil, err
+		}
+
+	case ExporterFile:
+		file, err := os.OpenFile(options.File, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return nil, err
+		}
+		if exporter, err = stdouttrace.New(stdouttrace.WithWriter(file)); err != nil {
+			file.Close()
+			return nil, err
+		}
+		closeFile = file.Close
+
+	default:
+		return nil, fmt.Errorf("unknown tracing exporter %q", options.Exporter)
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(options.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+
+	return func(ctx context.Context) error {
+		err := provider.Shutdown(ctx)
+		if closeFile != nil {
+			if closeErr := closeFile(); err == nil {
+				err = closeErr
+			}
+		}
+		return err
+	}, nil
+}
@@ -4,6 +4,94 @@ import { jwtDecode } from "jwt-decode";
 import { cookies } from "next/headers";
 import { redirect } from "next/navigation";
 
+// The access token lives for a few minutes and the refresh token for a month,
+// the default REFRESH_TOKEN_TTL_HOURS of the API. Both cookies outlive the
+// access token, which is refreshed when the API rejects it.
+const sessionMaxAge = 30 * 24 * 60 * 60;
+
+function storeSession(json: any) {
+  const options = {
+    httpOnly: true,
+    sameSite: "lax" as const,
+    secure: process.env.NODE_ENV === "production",
+    maxAge: sessionMaxAge,
+  };
+
+  cookies().set("session", json["token"], options);
+  cookies().set("refresh", json["refreshToken"], options);
+}
+
+function clearSession() {
+  cookies().delete("session");
+  cookies().delete("refresh");
+}
+
+// refreshSession exchanges the refresh token for a new pair of tokens, and
+// returns the new access token, or undefined when the session is over.
+async function refreshSession(): Promise<string | undefined> {
+  const refreshToken = cookies().get("refresh")?.value;
+  if (!refreshToken) {
+    return undefined;
+  }
+
+  const res = await fetch(`${process.env.API_URL}/api/auth/refresh`, {
+    method: "POST",
+
+    body: JSON.stringify({ refreshToken }),
+
+    headers: {
+      "Content-Type": "application/json",
+    },
+  });
+
+  if (!res.ok) {
+    return undefined;
+  }
+
+  const json = await res.json();
+  storeSession(json);
+  return json["token"];
+}
+
+// authorizedFetch calls the API with the access token of the session. When the
+// token is rejected, it is refreshed and the request sent once more; when that
+// fails too, the session is over and the user is sent to the login page.
+async function authorizedFetch(path: string, init: RequestInit = {}) {
+  const send = (token: string) =>
+    fetch(`${process.env.API_URL}${path}`, {
+      ...init,
+      headers: { ...init.headers, Authorization: `Bearer ${token}` },
+    });
+
+  const token = cookies().get("session")?.value;
+  if (token) {
+    const res = await send(token);
+    if (res.status !== 401) {
+      return res;
+    }
+  }
+
+  const refreshed = await refreshSession();
+  if (!refreshed) {
+    clearSession();
+    redirect(`/`);
+  }
+
+  return send(refreshed);
+}
+
+// sessionUserId returns the id of the signed in user, the sub claim of the
+// access token, read even when the token has expired.
+async function sessionUserId(): Promise<string> {
+  const token = cookies().get("session")?.value ?? (await refreshSession());
+  if (!token) {
+    redirect(`/`);
+  }
+
+  const jwt: any = jwtDecode(token)!;
+  return jwt["sub"];
+}
+
 export async function Register(dto: RegisterRequestDTO) {
   const res = await fetch(`${process.env.API_URL}/api/users`, {
     method: "POST",
@@ -27,9 +115,7 @@ export async function Register(dto: RegisterRequestDTO) {
 }
 
 export async function CreatePost(dto: RegisterPostDTO) {
-  const token = cookies().get("session")!.value;
-
-  const res = await fetch(`${process.env.API_URL}/api/posts`, {
+  const res = await authorizedFetch(`/api/posts`, {
     method: "POST",
 
     body: JSON.stringify({
@@ -38,7 +124,6 @@ export async function CreatePost(dto: RegisterPostDTO) {
     }),
 
     headers: {
-      Authorization: `Bearer ${token}`,
       "Content-Type": "application/json",
     },
   });
@@ -49,9 +134,7 @@ export async function CreatePost(dto: RegisterPostDTO) {
   }
 }
 export async function UpdatePost(dto: RegisterPostDTO, id: string) {
-  const token = cookies().get("session")!.value;
-
-  const res = await fetch(`${process.env.API_URL}/api/posts/${id}`, {
+  const res = await authorizedFetch(`/api/posts/${id}`, {
     method: "PUT",
 
     body: JSON.stringify({
@@ -60,7 +143,6 @@ export async function UpdatePost(dto: RegisterPostDTO, id: string) {
     }),
 
     headers: {
-      Authorization: `Bearer ${token}`,
       "Content-Type": "application/json",
     },
   });
@@ -72,10 +154,9 @@ export async function UpdatePost(dto: RegisterPostDTO, id: string) {
 }
 
 export async function UpdateUser(dto: RegisterRequestDTO) {
-  const token = cookies().get("session")!.value;
-  const jwt: any = jwtDecode(token)!;
+  const userId = await sessionUserId();
 
-  const res = await fetch(`${process.env.API_URL}/api/users/${jwt["userId"]}`, {
+  const res = await authorizedFetch(`/api/users/${userId}`, {
     method: "PUT",
 
     body: JSON.stringify({
@@ -85,7 +166,6 @@ export async function UpdateUser(dto: RegisterRequestDTO) {
     }),
 
     headers: {
-      Authorization: `Bearer ${token}`,
       "Content-Type": "application/json",
     },
   });
@@ -97,7 +177,24 @@ export async function UpdateUser(dto: RegisterRequestDTO) {
 }
 
 export async function Logout() {
-  cookies().delete("session");
+  const token = cookies().get("session")?.value;
+  const refreshToken = cookies().get("refresh")?.value;
+
+  // Revoking the tokens is best effort: the cookies are cleared regardless.
+  if (token) {
+    await fetch(`${process.env.API_URL}/api/auth/logout`, {
+      method: "POST",
+
+      body: JSON.stringify({ refreshToken }),
+
+      headers: {
+        Authorization: `Bearer ${token}`,
+        "Content-Type": "application/json",
+      },
+    }).catch(() => undefined);
+  }
+
+  clearSession();
   redirect(`/`);
 }
 
@@ -122,20 +219,18 @@ export async function Login(dto: AuthRequestDTO) {
 
   const json = await res.json();
 
-  cookies().set("session", json["token"]);
+  storeSession(json);
   redirect(`/home`);
 }
 
 export async function GetUser(): Promise<User> {
-  const token = cookies().get("session")!.value;
-  const jwt: any = jwtDecode(token)!;
+  const userId = await sessionUserId();
 
-  const res = await fetch(`${process.env.API_URL}/api/users/${jwt["userId"]}`, {
+  const res = await authorizedFetch(`/api/users/${userId}`, {
     method: "GET",
 
     headers: {
       "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
       "Access-Control-Allow-Origin": "*",
     },
   });
@@ -149,15 +244,13 @@ export async function GetUser(): Promise<User> {
 }
 
 export async function DeleteUser() {
-  const token = cookies().get("session")!.value;
-  const jwt: any = jwtDecode(token)!;
+  const userId = await sessionUserId();
 
-  const res = await fetch(`${process.env.API_URL}/api/users/${jwt["userId"]}`, {
+  const res = await authorizedFetch(`/api/users/${userId}`, {
     method: "DELETE",
 
     headers: {
       "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
       "Access-Control-Allow-Origin": "*",
     },
   });
@@ -167,19 +260,16 @@ export async function DeleteUser() {
     throw new Error(await res.text());
   }
 
-  cookies().delete("session");
+  clearSession();
   redirect(`/`);
 }
 
 export async function GetPost(id: string): Promise<Post> {
-  const token = cookies().get("session")!.value;
-
-  const res = await fetch(`${process.env.API_URL}/api/posts/${id}`, {
+  const res = await authorizedFetch(`/api/posts/${id}`, {
     method: "GET",
 
     headers: {
       "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
       "Access-Control-Allow-Origin": "*",
     },
   });
@@ -193,14 +283,11 @@ export async function GetPost(id: string): Promise<Post> {
 }
 
 export async function GetPosts(): Promise<Post[]> {
-  const token = cookies().get("session")!.value;
-
-  const res = await fetch(`${process.env.API_URL}/api/posts`, {
+  const res = await authorizedFetch(`/api/posts`, {
     method: "GET",
 
     headers: {
       "Content-Type": "application/json",
-      Authorization: `Bearer ${token}`,
       "Access-Control-Allow-Origin": "*",
     },
   });
@@ -210,16 +297,15 @@ export async function GetPosts(): Promise<Post[]> {
     throw new Error(await res.text());
   }
 
-  return res.json();
+  const page: PostPage = await res.json();
+  return page.posts;
 }
 
 export async function LikePost(id: string) {
-  const token = cookies().get("session")!.value;
-  const res = await fetch(`${process.env.API_URL}/api/posts/${id}/like`, {
+  const res = await authorizedFetch(`/api/posts/${id}/like`, {
     method: "POST",
 
     headers: {
-      Authorization: `Bearer ${token}`,
       "Content-Type": "application/json",
     },
   });
@@ -9,9 +9,14 @@ export const publicRoutes = ["/", "/register"];
 
 export default async function middleware(request: NextRequest) {
   const currentUser = cookies().get("session")?.value;
+  const refreshToken = cookies().get("refresh")?.value;
 
+  // An expired access token is refreshed by the API calls as long as the
+  // refresh token is there, so only a session without either is over.
   const isInvalid =
-    !currentUser || Date.now() > (jwtDecode(currentUser).exp ?? Date.now());
+    !refreshToken &&
+    (!currentUser ||
+      Date.now() > (jwtDecode(currentUser).exp ?? 0) * 1000);
 
   if (authRoutes.includes(request.nextUrl.pathname) && !isInvalid) {
     return NextResponse.redirect(new URL("/home", request.url));
@@ -19,8 +24,10 @@ export default async function middleware(request: NextRequest) {
 
   if (protectedRoutes.includes(request.nextUrl.pathname) && isInvalid) {
     request.cookies.delete("session");
+    request.cookies.delete("refresh");
     const response = NextResponse.redirect(new URL("/", request.url));
     response.cookies.delete("session");
+    response.cookies.delete("refresh");
 
     return response;
   }
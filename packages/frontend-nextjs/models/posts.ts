@@ -5,5 +5,14 @@ type Post = {
   authorId: string;
   authorNick: string;
   likes: number;
+  likedByMe: boolean;
+  reactions: Record<string, number>;
+  myReaction?: string;
+  commentsCount: number;
   createdat: Date;
 };
+
+type PostPage = {
+  posts: Post[];
+  nextCursor?: string;
+};